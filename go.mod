@@ -3,8 +3,40 @@ module source.toby3d.me/toby3d/sub
 go 1.20
 
 require (
-	github.com/brianvoe/gofakeit/v6 v6.20.2 // indirect
-	github.com/goccy/go-json v0.10.1 // indirect
-	github.com/google/go-cmp v0.5.9 // indirect
-	golang.org/x/exp v0.0.0-20230315142452-642cacee5cc0 // indirect
+	github.com/brianvoe/gofakeit/v6 v6.20.2
+	github.com/goccy/go-json v0.10.1
+	golang.org/x/exp v0.0.0-20230315142452-642cacee5cc0
+)
+
+require github.com/google/go-cmp v0.5.9
+
+require (
+	github.com/jmoiron/sqlx v1.3.5
+	github.com/lib/pq v1.10.9
+	github.com/pelletier/go-toml/v2 v2.0.9
+	go.etcd.io/bbolt v1.3.7
+	golang.org/x/net v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
+	modernc.org/sqlite v1.23.1
+)
+
+require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51 // indirect
+	github.com/mattn/go-isatty v0.0.16 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/mod v0.8.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	golang.org/x/tools v0.6.0 // indirect
+	lukechampine.com/uint128 v1.2.0 // indirect
+	modernc.org/cc/v3 v3.40.0 // indirect
+	modernc.org/ccgo/v3 v3.16.13 // indirect
+	modernc.org/libc v1.22.5 // indirect
+	modernc.org/mathutil v1.5.0 // indirect
+	modernc.org/memory v1.5.0 // indirect
+	modernc.org/opt v0.1.3 // indirect
+	modernc.org/strutil v1.1.3 // indirect
+	modernc.org/token v1.0.1 // indirect
 )
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"source.toby3d.me/toby3d/sub/internal/auth"
+	microsubhttpdelivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+)
+
+// Middleware authenticates requests by IndieAuth access tokens and puts the
+// token owner into the request context by "user" key.
+type Middleware struct {
+	auth auth.UseCase
+}
+
+func NewMiddleware(ucase auth.UseCase) *Middleware {
+	return &Middleware{
+		auth: ucase,
+	}
+}
+
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := extractToken(r)
+		if token == "" {
+			microsubhttpdelivery.WriteError(w, auth.ErrNoToken)
+
+			return
+		}
+
+		user, err := m.auth.Verify(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth.ErrToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+
+			// token endpoint is unreachable or answers with garbage
+			microsubhttpdelivery.WriteError(w, microsubhttpdelivery.NewUpstreamError(err))
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "user", user)))
+	})
+}
+
+// extractToken returns access token from Authorization header or
+// access_token form value.
+func extractToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+
+		return strings.TrimSpace(token)
+	}
+
+	return r.FormValue("access_token")
+}
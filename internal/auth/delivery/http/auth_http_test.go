@@ -0,0 +1,141 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/auth"
+	delivery "source.toby3d.me/toby3d/sub/internal/auth/delivery/http"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	microsubhttpdelivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+)
+
+type stubAuthUseCase struct {
+	user  *domain.User
+	token string
+}
+
+func (ucase stubAuthUseCase) Verify(_ context.Context, token string) (*domain.User, error) {
+	if token == "down" {
+		return nil, errors.New("cannot reach token endpoint")
+	}
+
+	if token != ucase.token {
+		return nil, auth.ErrToken
+	}
+
+	return ucase.user, nil
+}
+
+func TestMiddleware_Handler(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	handler := delivery.NewMiddleware(stubAuthUseCase{user: user, token: "secret"}).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if actual, _ := r.Context().Value("user").(*domain.User); actual == nil || actual.String() != user.String() {
+				t.Errorf("want %s user in context, got %v", user, actual)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	form := make(url.Values)
+	form.Set("access_token", "secret")
+
+	for name, tc := range map[string]struct {
+		req    func() *http.Request
+		code   string
+		expect int
+	}{
+		"header": {
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+				req.Header.Set("Authorization", "Bearer secret")
+
+				return req
+			},
+			expect: http.StatusNoContent,
+		},
+		"query": {
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "https://example.com/?access_token=secret", nil)
+			},
+			expect: http.StatusNoContent,
+		},
+		"form": {
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(form.Encode()))
+				req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+
+				return req
+			},
+			expect: http.StatusNoContent,
+		},
+		"missing": {
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+			},
+			code:   "unauthorized",
+			expect: http.StatusUnauthorized,
+		},
+		"invalid": {
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+				req.Header.Set("Authorization", "Bearer wrong")
+
+				return req
+			},
+			code:   "invalid_token",
+			expect: http.StatusUnauthorized,
+		},
+		"upstream": {
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+				req.Header.Set("Authorization", "Bearer down")
+
+				return req
+			},
+			code:   "server_error",
+			expect: http.StatusBadGateway,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tc.req())
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expect {
+				t.Errorf("want %d, got %d", tc.expect, resp.StatusCode)
+			}
+
+			if tc.code == "" {
+				return
+			}
+
+			if resp.Header.Get("WWW-Authenticate") == "" && resp.StatusCode != http.StatusBadGateway {
+				t.Error("want authentication challenge, got nothing")
+			}
+
+			actual := new(microsubhttpdelivery.ResponseError)
+			if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+				t.Fatal(err)
+			}
+
+			if actual.Error != tc.code || actual.ErrorDescription == "" {
+				t.Errorf("want '%s' error with description, got %+v", tc.code, actual)
+			}
+		})
+	}
+}
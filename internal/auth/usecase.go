@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type UseCase interface {
+	// Verify returns the user and scopes behind IndieAuth access token.
+	Verify(ctx context.Context, token string) (*domain.User, error)
+}
+
+var (
+	ErrNoToken = errors.New("access token is not provided")
+	ErrToken   = errors.New("access token is invalid or expired")
+)
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/sub/internal/auth"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
+)
+
+type (
+	authUseCase struct {
+		client   *http.Client
+		endpoint *url.URL
+		users    user.Repository
+		mutex    *sync.RWMutex
+		cache    map[string]verified
+		ttl      time.Duration
+	}
+
+	NewAuthUseCaseOptions struct {
+		Client *http.Client
+
+		// Endpoint is an IndieAuth token endpoint which verifies
+		// tokens.
+		Endpoint *url.URL
+
+		// Users registers every verified user. In-memory registry is
+		// used if nil.
+		Users user.Repository
+
+		// TTL is a time of caching verified tokens. DefaultTTL is used
+		// if zero.
+		TTL time.Duration
+	}
+
+	// verified is a cached result of successful token verification.
+	verified struct {
+		expiry time.Time
+		user   domain.User
+	}
+
+	// tokenResponse is a response of token endpoint to verification
+	// request. Active is provided only by token introspection endpoints.
+	tokenResponse struct {
+		Active   *bool  `json:"active,omitempty"`
+		Me       string `json:"me"`
+		ClientID string `json:"client_id"`
+		Scope    string `json:"scope"`
+		Exp      int64  `json:"exp,omitempty"`
+	}
+)
+
+// DefaultTTL is a default time of caching verified tokens.
+const DefaultTTL time.Duration = 5 * time.Minute
+
+// maxBodySize limits size of token endpoint response.
+const maxBodySize int64 = 1 << 20
+
+func NewAuthUseCase(opts NewAuthUseCaseOptions) auth.UseCase {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	if opts.Users == nil {
+		opts.Users = usermemoryrepo.NewMemoryUserRepository()
+	}
+
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+
+	return &authUseCase{
+		client:   opts.Client,
+		endpoint: opts.Endpoint,
+		users:    opts.Users,
+		mutex:    new(sync.RWMutex),
+		cache:    make(map[string]verified),
+		ttl:      opts.TTL,
+	}
+}
+
+func (ucase *authUseCase) Verify(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, auth.ErrToken
+	}
+
+	// tokens are secrets, so do not keep them in memory as is
+	hash := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(hash[:])
+
+	ucase.mutex.RLock()
+	result, ok := ucase.cache[key]
+	ucase.mutex.RUnlock()
+
+	if ok && time.Now().Before(result.expiry) {
+		out := result.user
+
+		return &out, nil
+	}
+
+	resp, err := ucase.verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	me, err := url.Parse(resp.Me)
+	if err != nil || (me.Scheme != "http" && me.Scheme != "https") || me.Host == "" {
+		return nil, fmt.Errorf("%w: token endpoint returned invalid me URL '%s'", auth.ErrToken, resp.Me)
+	}
+
+	// the same user must be recognized however token endpoint spells the URL
+	me.Host = strings.ToLower(me.Host)
+	me.Fragment, me.RawFragment = "", ""
+
+	if me.Path == "" {
+		me.Path, me.RawPath = "/", ""
+	}
+
+	result = verified{
+		user: domain.User{
+			URL:    me,
+			Scopes: domain.ParseScopes(resp.Scope),
+		},
+		expiry: time.Now().Add(ucase.ttl),
+	}
+
+	if err = ucase.users.Create(ctx, result.user); err != nil && !errors.Is(err, user.ErrExist) {
+		return nil, fmt.Errorf("cannot register user: %w", err)
+	}
+
+	if resp.Exp != 0 {
+		if exp := time.Unix(resp.Exp, 0); exp.Before(result.expiry) {
+			result.expiry = exp
+		}
+	}
+
+	ucase.mutex.Lock()
+	ucase.evict()
+	ucase.cache[key] = result
+	ucase.mutex.Unlock()
+
+	out := result.user
+
+	return &out, nil
+}
+
+func (ucase *authUseCase) verify(ctx context.Context, token string) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ucase.endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token verification request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", common.MIMEApplicationJSON)
+
+	resp, err := ucase.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden,
+		resp.StatusCode == http.StatusBadRequest:
+		return nil, auth.ErrToken
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("cannot verify token: unexpected token endpoint status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read token verification response: %w", err)
+	}
+
+	out := new(tokenResponse)
+
+	// old token endpoints respond in form encoding
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(common.HeaderContentType)); mediaType ==
+		common.MIMEApplicationForm {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode token verification response: %w", err)
+		}
+
+		out.Me, out.ClientID, out.Scope = values.Get("me"), values.Get("client_id"), values.Get("scope")
+	} else if err = json.Unmarshal(body, out); err != nil {
+		return nil, fmt.Errorf("cannot decode token verification response: %w", err)
+	}
+
+	if (out.Active != nil && !*out.Active) || strings.TrimSpace(out.Me) == "" {
+		return nil, auth.ErrToken
+	}
+
+	return out, nil
+}
+
+// evict removes expired tokens from cache. Must be called under lock.
+func (ucase *authUseCase) evict() {
+	now := time.Now()
+
+	for key := range ucase.cache {
+		if now.After(ucase.cache[key].expiry) {
+			delete(ucase.cache, key)
+		}
+	}
+}
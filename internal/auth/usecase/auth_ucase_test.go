@@ -0,0 +1,134 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/auth"
+	ucase "source.toby3d.me/toby3d/sub/internal/auth/usecase"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
+)
+
+func TestAuthUseCase_Verify(t *testing.T) {
+	t.Parallel()
+
+	requests := new(int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		switch r.Header.Get("Authorization") {
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		case "Bearer json":
+			w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+			fmt.Fprint(w, `{"me":"https://user.example.com/","client_id":"https://app.example.com/",`+
+				`"scope":"read follow create"}`)
+		case "Bearer form":
+			w.Header().Set(common.HeaderContentType, common.MIMEApplicationForm)
+			fmt.Fprint(w, "me=https%3A%2F%2Fuser.example.com%2F&scope=channels")
+		case "Bearer inactive":
+			w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSON)
+			fmt.Fprint(w, `{"active":false}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	endpoint, _ := url.Parse(srv.URL)
+	users := usermemoryrepo.NewMemoryUserRepository()
+	verifier := ucase.NewAuthUseCase(ucase.NewAuthUseCaseOptions{
+		Client:   srv.Client(),
+		Endpoint: endpoint,
+		Users:    users,
+	})
+
+	for _, tc := range []struct {
+		token  string
+		expect []domain.Scope
+	}{
+		{token: "json", expect: []domain.Scope{domain.ScopeRead, domain.ScopeFollow}},
+		{token: "form", expect: []domain.Scope{domain.ScopeChannels}},
+	} {
+		for i := 0; i < 2; i++ {
+			actual, err := verifier.Verify(context.Background(), tc.token)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.token, err)
+			}
+
+			if actual.String() != "https://user.example.com/" {
+				t.Errorf("%s: want https://user.example.com/ user, got %s", tc.token, actual)
+			}
+
+			if len(actual.Scopes) != len(tc.expect) {
+				t.Fatalf("%s: want %v scopes, got %v", tc.token, tc.expect, actual.Scopes)
+			}
+
+			for j := range tc.expect {
+				if !actual.HasScope(tc.expect[j]) {
+					t.Errorf("%s: want %s scope, got %v", tc.token, tc.expect[j], actual.Scopes)
+				}
+			}
+		}
+	}
+
+	// verified tokens are cached
+	if actual := atomic.LoadInt32(requests); actual != 2 {
+		t.Errorf("want %d requests to token endpoint, got %d", 2, actual)
+	}
+
+	// verified users are registered once
+	registered, err := users.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(registered) != 1 || registered[0].String() != "https://user.example.com/" {
+		t.Errorf("want registered https://user.example.com/ user, got %+v", registered)
+	}
+
+	for _, token := range []string{"", "inactive", "unknown"} {
+		if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, auth.ErrToken) {
+			t.Errorf("'%s': want %v, got %v", token, auth.ErrToken, err)
+		}
+	}
+}
+
+func TestAuthUseCase_Verify_Canonical(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(common.HeaderContentType, common.MIMEApplicationForm)
+		fmt.Fprint(w, url.Values{"me": []string{r.Header.Get("Authorization")[len("Bearer "):]}}.Encode())
+	}))
+	t.Cleanup(srv.Close)
+
+	endpoint, _ := url.Parse(srv.URL)
+	verifier := ucase.NewAuthUseCase(ucase.NewAuthUseCaseOptions{
+		Client:   srv.Client(),
+		Endpoint: endpoint,
+	})
+
+	for me, expect := range map[string]string{
+		"https://user.example.com/":          "https://user.example.com/",
+		"https://User.Example.COM":           "https://user.example.com/",
+		"HTTPS://user.example.com/#me":       "https://user.example.com/",
+		"https://user.example.com/Blog#me":   "https://user.example.com/Blog",
+		"https://user.example.com:8080?a=Hi": "https://user.example.com:8080/?a=Hi",
+	} {
+		actual, err := verifier.Verify(context.Background(), me)
+		if err != nil {
+			t.Fatalf("%s: %v", me, err)
+		}
+
+		if actual.String() != expect {
+			t.Errorf("%s: want %s user, got %s", me, expect, actual)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package authortest contains conformance tests which every implementation of
+// author.Repository must pass.
+package authortest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+// TestRepository runs conformance tests against the empty repositories
+// returned by newRepository.
+func TestRepository(t *testing.T, newRepository func(tb testing.TB) author.Repository) {
+	t.Helper()
+
+	for name, test := range map[string]func(*testing.T, author.Repository){
+		"Create":    testCreate,
+		"Fetch":     testFetch,
+		"Delete":    testDelete,
+		"Isolation": testIsolation,
+	} {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			test(t, newRepository(t))
+		})
+	}
+}
+
+var alice = domain.Card{
+	Type:  "card",
+	Name:  "Alice",
+	URL:   "https://alice.example.com/",
+	Photo: "https://alice.example.com/photo.jpg",
+}
+
+func testCreate(t *testing.T, repo author.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	src, _ := url.Parse(alice.URL)
+
+	if err := repo.Create(ctx, *user, "home", alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Create(ctx, *user, "home", alice); !errors.Is(err, author.ErrExist) {
+		t.Errorf("want %v for duplicate, got %v", author.ErrExist, err)
+	}
+
+	actual, err := repo.Get(ctx, *user, "home", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(&alice, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err = repo.Get(ctx, *user, common.ChannelGlobal, src); !errors.Is(err, author.ErrNotExist) {
+		t.Errorf("want %v for author of another channel, got %v", author.ErrNotExist, err)
+	}
+}
+
+func testFetch(t *testing.T, repo author.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	bob := domain.Card{Type: "card", URL: "https://bob.example.com/"}
+
+	for _, c := range []domain.Card{alice, bob} {
+		if err := repo.Create(ctx, *user, common.ChannelGlobal, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Create(ctx, *user, "home", bob); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := repo.Fetch(ctx, *user, common.ChannelGlobal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]domain.Card{alice, bob}, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	if actual, err = repo.Fetch(ctx, *user, "unknown"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actual) != 0 {
+		t.Errorf("want no authors of unknown channel, got %+v", actual)
+	}
+}
+
+func testDelete(t *testing.T, repo author.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	src, _ := url.Parse(alice.URL)
+
+	for _, cid := range []string{"home", common.ChannelGlobal} {
+		if err := repo.Create(ctx, *user, cid, alice); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Delete(ctx, *user, "home", src); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Get(ctx, *user, "home", src); !errors.Is(err, author.ErrNotExist) {
+		t.Errorf("want %v for deleted author, got %v", author.ErrNotExist, err)
+	}
+
+	// the same author in other channel is untouched
+	if _, err := repo.Get(ctx, *user, common.ChannelGlobal, src); err != nil {
+		t.Error(err)
+	}
+}
+
+func testIsolation(t *testing.T, repo author.Repository) {
+	ctx := context.Background()
+	owner := domain.TestUser(t)
+	stranger := &domain.User{URL: &url.URL{Scheme: "https", Host: "stranger.example.com", Path: "/"}}
+	src, _ := url.Parse(alice.URL)
+
+	if err := repo.Create(ctx, *owner, common.ChannelGlobal, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Get(ctx, *stranger, common.ChannelGlobal, src); !errors.Is(err, author.ErrNotExist) {
+		t.Errorf("want %v for author of another user, got %v", author.ErrNotExist, err)
+	}
+
+	authors, err := repo.Fetch(ctx, *stranger, common.ChannelGlobal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(authors) != 0 {
+		t.Errorf("want no authors of another user, got %+v", authors)
+	}
+
+	// the same author may be listed by different users
+	if err = repo.Create(ctx, *stranger, common.ChannelGlobal, alice); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestLists checks that first and second repositories, which are different
+// lists in the same storage, do not share authors.
+func TestLists(t *testing.T, first, second author.Repository) {
+	t.Helper()
+
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	src, _ := url.Parse(alice.URL)
+
+	if err := first.Create(ctx, *user, common.ChannelGlobal, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := second.Get(ctx, *user, common.ChannelGlobal, src); !errors.Is(err, author.ErrNotExist) {
+		t.Errorf("want %v for author of another list, got %v", author.ErrNotExist, err)
+	}
+
+	if err := second.Create(ctx, *user, common.ChannelGlobal, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := second.Delete(ctx, *user, common.ChannelGlobal, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := first.Get(ctx, *user, common.ChannelGlobal, src); err != nil {
+		t.Errorf("want author kept in first list, got %v", err)
+	}
+}
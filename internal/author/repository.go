@@ -0,0 +1,30 @@
+// Package author describes lists of users, like muted or blocked ones, kept
+// per channel or globally.
+package author
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type Repository interface {
+	Create(ctx context.Context, user domain.User, channel string, author domain.Card) error
+	Get(ctx context.Context, user domain.User, channel string, u *url.URL) (*domain.Card, error)
+	Fetch(ctx context.Context, user domain.User, channel string) ([]domain.Card, error)
+	Delete(ctx context.Context, user domain.User, channel string, u *url.URL) error
+}
+
+// Lists of authors which are kept in the same storage by persistent
+// repositories.
+const (
+	ListMutes  string = "mutes"
+	ListBlocks string = "blocks"
+)
+
+var (
+	ErrNotExist = errors.New("user is not listed")
+	ErrExist    = errors.New("user already listed")
+)
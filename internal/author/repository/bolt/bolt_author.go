@@ -0,0 +1,172 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	database "source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	boltAuthorRepository struct {
+		db     *bolt.DB
+		bucket []byte
+	}
+
+	// Author is a stored value of listed user.
+	Author struct {
+		URL   string `json:"url"`
+		Name  string `json:"name,omitempty"`
+		Photo string `json:"photo,omitempty"`
+	}
+)
+
+// NewBoltAuthorRepository creates repository of authors list, like
+// author.ListMutes, in database opened by bolt.Open. Each list is a bucket
+// of user with nested bucket of authors by their URL for each channel.
+func NewBoltAuthorRepository(db *bolt.DB, list string) author.Repository {
+	return &boltAuthorRepository{
+		db:     db,
+		bucket: []byte(list),
+	}
+}
+
+func (repo *boltAuthorRepository) Create(ctx context.Context, u domain.User, cid string, c domain.Card) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		authors, err := repo.channelBucket(tx, u, cid)
+		if err != nil {
+			return err
+		}
+
+		key := []byte(c.URL)
+		if authors.Get(key) != nil {
+			return author.ErrExist
+		}
+
+		src, err := json.Marshal(NewAuthor(c))
+		if err != nil {
+			return fmt.Errorf("cannot encode author: %w", err)
+		}
+
+		return authors.Put(key, src)
+	})
+}
+
+func (repo *boltAuthorRepository) Get(ctx context.Context, u domain.User, cid string, src *url.URL) (
+	*domain.Card, error,
+) {
+	var out *domain.Card
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		authors, err := repo.channelBucket(tx, u, cid)
+		if err != nil {
+			return err
+		}
+
+		if authors == nil {
+			return author.ErrNotExist
+		}
+
+		value := authors.Get([]byte(src.String()))
+		if value == nil {
+			return author.ErrNotExist
+		}
+
+		out, err = decode(value)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (repo *boltAuthorRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Card, error) {
+	out := make([]domain.Card, 0)
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		authors, err := repo.channelBucket(tx, u, cid)
+		if err != nil || authors == nil {
+			return err
+		}
+
+		return authors.ForEach(func(_, value []byte) error {
+			c, err := decode(value)
+			if err != nil {
+				return err
+			}
+
+			out = append(out, *c)
+
+			return nil
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("cannot fetch authors: %w", err)
+	}
+
+	return out, nil
+}
+
+func (repo *boltAuthorRepository) Delete(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		authors, err := repo.channelBucket(tx, u, cid)
+		if err != nil {
+			return err
+		}
+
+		return authors.Delete([]byte(src.String()))
+	})
+}
+
+func NewAuthor(c domain.Card) *Author {
+	return &Author{
+		URL:   c.URL,
+		Name:  c.Name,
+		Photo: c.Photo,
+	}
+}
+
+func (a Author) Populate() *domain.Card {
+	return &domain.Card{
+		Type:  "card",
+		URL:   a.URL,
+		Name:  a.Name,
+		Photo: a.Photo,
+	}
+}
+
+// channelBucket returns bucket of authors listed in user channel. It returns
+// nil in read-only transaction if there are no such authors yet.
+func (repo *boltAuthorRepository) channelBucket(tx *bolt.Tx, u domain.User, cid string) (*bolt.Bucket, error) {
+	list, err := database.UserBucket(tx, u, repo.bucket)
+	if err != nil || list == nil {
+		return nil, err
+	}
+
+	if !tx.Writable() {
+		return list.Bucket([]byte(cid)), nil
+	}
+
+	out, err := list.CreateBucketIfNotExists([]byte(cid))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create channel bucket: %w", err)
+	}
+
+	return out, nil
+}
+
+func decode(src []byte) (*domain.Card, error) {
+	a := new(Author)
+	if err := json.Unmarshal(src, a); err != nil {
+		return nil, fmt.Errorf("cannot decode author: %w", err)
+	}
+
+	return a.Populate(), nil
+}
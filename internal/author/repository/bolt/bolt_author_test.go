@@ -0,0 +1,43 @@
+package bolt_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/author/authortest"
+	repository "source.toby3d.me/toby3d/sub/internal/author/repository/bolt"
+	"source.toby3d.me/toby3d/sub/internal/database/bolt"
+)
+
+func TestBoltAuthorRepository(t *testing.T) {
+	t.Parallel()
+
+	authortest.TestRepository(t, func(tb testing.TB) author.Repository {
+		return repository.NewBoltAuthorRepository(open(tb), author.ListMutes)
+	})
+}
+
+func TestBoltAuthorRepository_Lists(t *testing.T) {
+	t.Parallel()
+
+	db := open(t)
+
+	authortest.TestLists(t, repository.NewBoltAuthorRepository(db, author.ListMutes),
+		repository.NewBoltAuthorRepository(db, author.ListBlocks))
+}
+
+func open(tb testing.TB) *bbolt.DB {
+	tb.Helper()
+
+	db, err := bolt.Open(filepath.Join(tb.TempDir(), "sub.db"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type memoryAuthorRepository struct {
+	mutex   *sync.RWMutex
+	authors map[string]map[string][]domain.Card
+}
+
+func NewMemoryAuthorRepository() author.Repository {
+	return &memoryAuthorRepository{
+		mutex:   new(sync.RWMutex),
+		authors: make(map[string]map[string][]domain.Card, 0),
+	}
+}
+
+func (repo *memoryAuthorRepository) Create(ctx context.Context, u domain.User, cid string, card domain.Card) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.index(u, cid, card.URL) != -1 {
+		return author.ErrExist
+	}
+
+	if _, ok := repo.authors[u.String()]; !ok {
+		repo.authors[u.String()] = make(map[string][]domain.Card)
+	}
+
+	repo.authors[u.String()][cid] = append(repo.authors[u.String()][cid], card)
+
+	return nil
+}
+
+func (repo *memoryAuthorRepository) Get(ctx context.Context, u domain.User, cid string, src *url.URL) (*domain.Card, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	i := repo.index(u, cid, src.String())
+	if i == -1 {
+		return nil, author.ErrNotExist
+	}
+
+	out := repo.authors[u.String()][cid][i]
+
+	return &out, nil
+}
+
+func (repo *memoryAuthorRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Card, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	return append(make([]domain.Card, 0), repo.authors[u.String()][cid]...), nil
+}
+
+func (repo *memoryAuthorRepository) Delete(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if i := repo.index(u, cid, src.String()); i != -1 {
+		repo.authors[u.String()][cid] = slices.Delete(repo.authors[u.String()][cid], i, i+1)
+	}
+
+	return nil
+}
+
+// index returns position of listed author in user channel or -1 if there is
+// none. Must be called under lock.
+func (repo *memoryAuthorRepository) index(u domain.User, cid string, src string) int {
+	authors := repo.authors[u.String()][cid]
+
+	for i := range authors {
+		if authors[i].URL == src {
+			return i
+		}
+	}
+
+	return -1
+}
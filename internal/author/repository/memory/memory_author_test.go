@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/author/authortest"
+	repository "source.toby3d.me/toby3d/sub/internal/author/repository/memory"
+)
+
+func TestMemoryAuthorRepository(t *testing.T) {
+	t.Parallel()
+
+	authortest.TestRepository(t, func(tb testing.TB) author.Repository {
+		return repository.NewMemoryAuthorRepository()
+	})
+}
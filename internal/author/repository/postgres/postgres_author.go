@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	postgresAuthorRepository struct {
+		db   *sqlx.DB
+		list string
+	}
+
+	// Author is a row of authors table.
+	Author struct {
+		Me      string `db:"me"`
+		List    string `db:"list"`
+		Channel string `db:"channel"`
+		URL     string `db:"url"`
+		Name    string `db:"name"`
+		Photo   string `db:"photo"`
+	}
+)
+
+const (
+	queryTable  string = "SELECT me, list, channel, url, name, photo FROM authors"
+	queryGet    string = queryTable + " WHERE me = $1 AND list = $2 AND channel = $3 AND url = $4"
+	queryFetch  string = queryTable + " WHERE me = $1 AND list = $2 AND channel = $3 ORDER BY url"
+	queryCreate string = `INSERT INTO authors (me, list, channel, url, name, photo)
+		VALUES (:me, :list, :channel, :url, :name, :photo)
+		ON CONFLICT (me, list, channel, url) DO NOTHING`
+	queryDelete string = "DELETE FROM authors WHERE me = $1 AND list = $2 AND channel = $3 AND url = $4"
+)
+
+// NewPostgresAuthorRepository creates repository of authors list, like
+// author.ListMutes, in database opened by postgres.Open.
+func NewPostgresAuthorRepository(db *sqlx.DB, list string) author.Repository {
+	return &postgresAuthorRepository{
+		db:   db,
+		list: list,
+	}
+}
+
+func (repo *postgresAuthorRepository) Create(ctx context.Context, u domain.User, cid string, c domain.Card) error {
+	result, err := repo.db.NamedExecContext(ctx, queryCreate, NewAuthor(u, repo.list, cid, c))
+	if err != nil {
+		return fmt.Errorf("cannot create author: %w", err)
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return author.ErrExist
+	}
+
+	return nil
+}
+
+func (repo *postgresAuthorRepository) Get(ctx context.Context, u domain.User, cid string, src *url.URL) (
+	*domain.Card, error,
+) {
+	row := new(Author)
+	if err := repo.db.GetContext(ctx, row, queryGet, u.String(), repo.list, cid, src.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, author.ErrNotExist
+		}
+
+		return nil, fmt.Errorf("cannot get author: %w", err)
+	}
+
+	return row.Populate(), nil
+}
+
+func (repo *postgresAuthorRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Card, error) {
+	rows := make([]Author, 0)
+	if err := repo.db.SelectContext(ctx, &rows, queryFetch, u.String(), repo.list, cid); err != nil {
+		return nil, fmt.Errorf("cannot fetch authors: %w", err)
+	}
+
+	out := make([]domain.Card, 0, len(rows))
+	for i := range rows {
+		out = append(out, *rows[i].Populate())
+	}
+
+	return out, nil
+}
+
+func (repo *postgresAuthorRepository) Delete(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	if _, err := repo.db.ExecContext(ctx, queryDelete, u.String(), repo.list, cid, src.String()); err != nil {
+		return fmt.Errorf("cannot delete author: %w", err)
+	}
+
+	return nil
+}
+
+func NewAuthor(u domain.User, list, cid string, c domain.Card) *Author {
+	return &Author{
+		Me:      u.String(),
+		List:    list,
+		Channel: cid,
+		URL:     c.URL,
+		Name:    c.Name,
+		Photo:   c.Photo,
+	}
+}
+
+func (a Author) Populate() *domain.Card {
+	return &domain.Card{
+		Type:  "card",
+		URL:   a.URL,
+		Name:  a.Name,
+		Photo: a.Photo,
+	}
+}
@@ -0,0 +1,27 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/author/authortest"
+	repository "source.toby3d.me/toby3d/sub/internal/author/repository/postgres"
+	"source.toby3d.me/toby3d/sub/internal/database/postgres"
+)
+
+func TestPostgresAuthorRepository(t *testing.T) {
+	t.Parallel()
+
+	authortest.TestRepository(t, func(tb testing.TB) author.Repository {
+		return repository.NewPostgresAuthorRepository(postgres.TestDatabase(tb), author.ListMutes)
+	})
+}
+
+func TestPostgresAuthorRepository_Lists(t *testing.T) {
+	t.Parallel()
+
+	db := postgres.TestDatabase(t)
+
+	authortest.TestLists(t, repository.NewPostgresAuthorRepository(db, author.ListMutes),
+		repository.NewPostgresAuthorRepository(db, author.ListBlocks))
+}
@@ -0,0 +1,43 @@
+package sqlite3_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/author/authortest"
+	repository "source.toby3d.me/toby3d/sub/internal/author/repository/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/database/sqlite3"
+)
+
+func TestSQLite3AuthorRepository(t *testing.T) {
+	t.Parallel()
+
+	authortest.TestRepository(t, func(tb testing.TB) author.Repository {
+		return repository.NewSQLite3AuthorRepository(open(tb), author.ListMutes)
+	})
+}
+
+func TestSQLite3AuthorRepository_Lists(t *testing.T) {
+	t.Parallel()
+
+	db := open(t)
+
+	authortest.TestLists(t, repository.NewSQLite3AuthorRepository(db, author.ListMutes),
+		repository.NewSQLite3AuthorRepository(db, author.ListBlocks))
+}
+
+func open(tb testing.TB) *sqlx.DB {
+	tb.Helper()
+
+	db, err := sqlite3.Open(context.Background(), ":memory:")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
@@ -0,0 +1,23 @@
+package block
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type UseCase interface {
+	// Fetch returns users blocked in channel. Use common.ChannelGlobal for
+	// users blocked everywhere.
+	Fetch(ctx context.Context, u domain.User, channel string) ([]domain.Card, error)
+
+	// Block removes all entries of author from channel, or from all
+	// channels if it's common.ChannelGlobal, and prevents ingestion of new
+	// ones. Already blocked author is returned as is.
+	Block(ctx context.Context, u domain.User, channel string, author *url.URL) (*domain.Card, error)
+	Unblock(ctx context.Context, u domain.User, channel string, author *url.URL) error
+}
+
+var ErrNotExist = errors.New("user is not blocked")
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/block"
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+)
+
+type blockUseCase struct {
+	blocks   author.Repository
+	channels channel.Repository
+	entries  timeline.Repository
+}
+
+func NewBlockUseCase(blocks author.Repository, channels channel.Repository, entries timeline.Repository) block.UseCase {
+	return &blockUseCase{
+		blocks:   blocks,
+		channels: channels,
+		entries:  entries,
+	}
+}
+
+func (ucase *blockUseCase) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Card, error) {
+	authors, err := ucase.blocks.Fetch(ctx, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch blocked users: %w", err)
+	}
+
+	return authors, nil
+}
+
+func (ucase *blockUseCase) Block(ctx context.Context, u domain.User, cid string, src *url.URL) (*domain.Card, error) {
+	cids := []string{cid}
+
+	switch cid {
+	default:
+		if _, err := ucase.channels.Get(ctx, u, cid); err != nil {
+			return nil, fmt.Errorf("cannot find channel for block: %w", err)
+		}
+	case common.ChannelNotifications:
+	case common.ChannelGlobal:
+		cids = []string{common.ChannelNotifications}
+
+		// user may have no channels except notifications yet
+		channels, _ := ucase.channels.Fetch(ctx, u)
+		for i := range channels {
+			cids = append(cids, channels[i].UID)
+		}
+	}
+
+	card := domain.Card{Type: "card", URL: src.String()}
+
+	// remember how the author looks like for listing blocks
+	for _, cid := range cids {
+		entries, err := ucase.entries.Fetch(ctx, u, cid)
+		if err != nil {
+			return nil, fmt.Errorf("cannot fetch entries of blocking user: %w", err)
+		}
+
+		for i := range entries {
+			if entries[i].Author != nil && entries[i].Author.URL == card.URL && entries[i].Author.Name != "" {
+				card.Name, card.Photo = entries[i].Author.Name, entries[i].Author.Photo
+
+				break
+			}
+		}
+
+		if card.Name != "" {
+			break
+		}
+	}
+
+	// block goes first, so entries fetched while removing the existing ones
+	// are not stored anymore
+	if err := ucase.blocks.Create(ctx, u, cid, card); err != nil && !errors.Is(err, author.ErrExist) {
+		return nil, fmt.Errorf("cannot block user: %w", err)
+	}
+
+	for _, cid := range cids {
+		entries, err := ucase.entries.Fetch(ctx, u, cid)
+		if err != nil {
+			return nil, fmt.Errorf("cannot fetch entries of blocked user: %w", err)
+		}
+
+		for i := range entries {
+			if entries[i].Author == nil || entries[i].Author.URL != card.URL {
+				continue
+			}
+
+			if err = ucase.entries.Delete(ctx, u, entries[i].ID); err != nil && !errors.Is(err, timeline.ErrNotExist) {
+				return nil, fmt.Errorf("cannot remove entry of blocked user: %w", err)
+			}
+		}
+	}
+
+	out, err := ucase.blocks.Get(ctx, u, cid, src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot return blocked user: %w", err)
+	}
+
+	return out, nil
+}
+
+func (ucase *blockUseCase) Unblock(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	if _, err := ucase.blocks.Get(ctx, u, cid, src); err != nil {
+		if errors.Is(err, author.ErrNotExist) {
+			err = block.ErrNotExist
+		}
+
+		return fmt.Errorf("cannot find unblocking user: %w", err)
+	}
+
+	if err := ucase.blocks.Delete(ctx, u, cid, src); err != nil {
+		return fmt.Errorf("cannot unblock user: %w", err)
+	}
+
+	return nil
+}
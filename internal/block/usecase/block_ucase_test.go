@@ -0,0 +1,167 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	authormemoryrepo "source.toby3d.me/toby3d/sub/internal/author/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/block"
+	ucase "source.toby3d.me/toby3d/sub/internal/block/usecase"
+	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+)
+
+// blockedEntries is a timeline repository which reports removal of entries
+// whose author is not blocked yet.
+type blockedEntries struct {
+	timeline.Repository
+	blocks  author.Repository
+	channel string
+	t       *testing.T
+}
+
+func (repo blockedEntries) Delete(ctx context.Context, u domain.User, id string) error {
+	e, err := repo.Get(ctx, u, id)
+	if err != nil {
+		return err
+	}
+
+	src, _ := url.Parse(e.Author.URL)
+	if _, err = repo.blocks.Get(ctx, u, repo.channel, src); err != nil {
+		repo.t.Errorf("expect %s blocked before removal of entries, got %v", src, err)
+	}
+
+	return repo.Repository.Delete(ctx, u, id)
+}
+
+func TestBlockUseCase_Block(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	if err := channels.Create(context.Background(), *user, *channel); err != nil {
+		t.Fatal(err)
+	}
+
+	blocked, other := domain.TestEntry(t), domain.TestEntry(t)
+	blocked.Channel, other.Channel = channel.UID, channel.UID
+
+	// the same author in another channel
+	notification := domain.TestEntry(t)
+	notification.Channel, notification.Author = common.ChannelNotifications, blocked.Author
+
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	for _, e := range []*domain.Entry{blocked, other, notification} {
+		if err := entries.Create(context.Background(), *user, *e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	author, _ := url.Parse(blocked.Author.URL)
+	blocks := ucase.NewBlockUseCase(authormemoryrepo.NewMemoryAuthorRepository(), channels, entries)
+
+	if _, err := blocks.Block(context.Background(), *user, channel.UID, author); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 || result[0].ID != other.ID {
+		t.Errorf("expect single %s entry, got %+v", other.ID, result)
+	}
+
+	if _, err = entries.Get(context.Background(), *user, notification.ID); err != nil {
+		t.Errorf("expect entry in other channel is kept, got %v", err)
+	}
+
+	if _, err = blocks.Block(context.Background(), *user, common.ChannelGlobal, author); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = entries.Get(context.Background(), *user, notification.ID); err == nil {
+		t.Error("expect entry removed from all channels by global block")
+	}
+
+	actual, err := blocks.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []domain.Card{{Type: "card", Name: blocked.Author.Name, URL: blocked.Author.URL}}
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestBlockUseCase_Unblock(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	author := domain.Card{Type: "card", URL: "https://example.com/"}
+	src, _ := url.Parse(author.URL)
+	blocks := authormemoryrepo.NewMemoryAuthorRepository()
+
+	if err := blocks.Create(context.Background(), *user, common.ChannelGlobal, author); err != nil {
+		t.Fatal(err)
+	}
+
+	blockUseCase := ucase.NewBlockUseCase(blocks, channelmemoryrepo.NewMemoryChannelRepository(),
+		timelinememoryrepo.NewMemoryTimelineRepository())
+
+	if err := blockUseCase.Unblock(context.Background(), *user, common.ChannelGlobal, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := blockUseCase.Unblock(context.Background(), *user, common.ChannelGlobal, src); !errors.Is(err,
+		block.ErrNotExist) {
+		t.Errorf("expect %v, got %v", block.ErrNotExist, err)
+	}
+}
+
+func TestBlockUseCase_Block_Order(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	if err := channels.Create(context.Background(), *user, *channel); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := domain.TestEntry(t)
+	entry.Channel = channel.UID
+
+	authors := authormemoryrepo.NewMemoryAuthorRepository()
+	entries := blockedEntries{
+		Repository: timelinememoryrepo.NewMemoryTimelineRepository(),
+		blocks:     authors,
+		channel:    channel.UID,
+		t:          t,
+	}
+
+	if err := entries.Create(context.Background(), *user, *entry); err != nil {
+		t.Fatal(err)
+	}
+
+	src, _ := url.Parse(entry.Author.URL)
+	if _, err := ucase.NewBlockUseCase(authors, channels, entries).
+		Block(context.Background(), *user, channel.UID, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := entries.Get(context.Background(), *user, entry.ID); err == nil {
+		t.Error("expect entry of blocked user removed")
+	}
+}
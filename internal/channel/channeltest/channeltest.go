@@ -0,0 +1,353 @@
+// Package channeltest contains conformance tests which every implementation of
+// channel.Repository must pass.
+package channeltest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+// TestRepository runs conformance tests against the empty repositories
+// returned by newRepository.
+func TestRepository(t *testing.T, newRepository func(tb testing.TB) channel.Repository) {
+	t.Helper()
+
+	for name, test := range map[string]func(*testing.T, channel.Repository){
+		"Create":         testCreate,
+		"Fetch":          testFetch,
+		"Update":         testUpdate,
+		"UpdateRollback": testUpdateRollback,
+		"Delete":         testDelete,
+		"Isolation":      testIsolation,
+		"Retention":      testRetention,
+		"FetchAll":       testFetchAll,
+	} {
+		name, test := name, test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			test(t, newRepository(t))
+		})
+	}
+}
+
+var opts = cmp.AllowUnexported(domain.UnreadMode{})
+
+func testCreate(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	first, second := domain.TestChannel(t), domain.TestChannel(t)
+	second.Unread = domain.UnreadModeIndicator
+
+	for _, c := range []*domain.Channel{first, second} {
+		if err := repo.Create(ctx, *user, *c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Create(ctx, *user, *first); !errors.Is(err, channel.ErrExist) {
+		t.Errorf("want %v for duplicate, got %v", channel.ErrExist, err)
+	}
+
+	for i, c := range []*domain.Channel{first, second} {
+		c.Weight = i
+
+		actual, err := repo.Get(ctx, *user, c.UID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(c, actual, opts); diff != "" {
+			t.Error(diff)
+		}
+	}
+
+	if _, err := repo.Get(ctx, *user, "unknown"); !errors.Is(err, channel.ErrNotExist) {
+		t.Errorf("want %v for unknown channel, got %v", channel.ErrNotExist, err)
+	}
+}
+
+func testFetch(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+
+	actual, err := repo.Fetch(ctx, *user)
+	if err != nil {
+		t.Fatalf("want no error for user without channels, got %v", err)
+	}
+
+	if len(actual) != 0 {
+		t.Errorf("want no channels, got %+v", actual)
+	}
+
+	expect := make([]string, 3)
+
+	for i := range expect {
+		c := domain.TestChannel(t)
+		if err = repo.Create(ctx, *user, *c); err != nil {
+			t.Fatal(err)
+		}
+
+		// reverse order by weights
+		if err = repo.Update(ctx, *user, c.UID, func(tx *domain.Channel) (*domain.Channel, error) {
+			tx.Weight = len(expect) - i
+
+			return tx, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		expect[len(expect)-1-i] = c.UID
+	}
+
+	for i := 0; i < 2; i++ {
+		channels, err := repo.Fetch(ctx, *user)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		uids := make([]string, 0, len(channels))
+		for j := range channels {
+			uids = append(uids, channels[j].UID)
+		}
+
+		if diff := cmp.Diff(expect, uids); diff != "" {
+			t.Errorf("#%d: %s", i, diff)
+		}
+
+		// changes of result must not affect stored channels
+		for j := range channels {
+			channels[j].Weight = -j
+		}
+	}
+}
+
+func testUpdate(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	expect := domain.TestChannel(t)
+
+	if err := repo.Create(ctx, *user, *expect); err != nil {
+		t.Fatal(err)
+	}
+
+	expect.Name = "Updated"
+	expect.Unread = domain.UnreadModeOff
+	expect.Weight = 42
+
+	if err := repo.Update(ctx, *user, expect.UID, func(tx *domain.Channel) (*domain.Channel, error) {
+		tx.Name = expect.Name
+		tx.Unread = expect.Unread
+		tx.Weight = expect.Weight
+
+		return tx, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := repo.Get(ctx, *user, expect.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expect, actual, opts); diff != "" {
+		t.Error(diff)
+	}
+
+	// returned channel is a copy
+	actual.Name = "Changed"
+
+	if again, _ := repo.Get(ctx, *user, expect.UID); again == nil || again.Name != expect.Name {
+		t.Errorf("want '%s' name of stored channel, got %+v", expect.Name, again)
+	}
+
+	if err = repo.Update(ctx, *user, "unknown", func(tx *domain.Channel) (*domain.Channel, error) {
+		return tx, nil
+	}); !errors.Is(err, channel.ErrNotExist) {
+		t.Errorf("want %v for unknown channel, got %v", channel.ErrNotExist, err)
+	}
+}
+
+func testUpdateRollback(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	expect := domain.TestChannel(t)
+
+	if err := repo.Create(ctx, *user, *expect); err != nil {
+		t.Fatal(err)
+	}
+
+	errAbort := errors.New("abort")
+
+	if err := repo.Update(ctx, *user, expect.UID, func(tx *domain.Channel) (*domain.Channel, error) {
+		tx.Name = "Aborted"
+
+		return nil, errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Errorf("want %v, got %v", errAbort, err)
+	}
+
+	actual, err := repo.Get(ctx, *user, expect.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.Name != expect.Name {
+		t.Errorf("want '%s' name after failed update, got '%s'", expect.Name, actual.Name)
+	}
+}
+
+func testDelete(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	first, second, third := domain.TestChannel(t), domain.TestChannel(t), domain.TestChannel(t)
+
+	for _, c := range []*domain.Channel{first, second} {
+		if err := repo.Create(ctx, *user, *c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Delete(ctx, *user, second.UID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Get(ctx, *user, second.UID); !errors.Is(err, channel.ErrNotExist) {
+		t.Errorf("want %v for deleted channel, got %v", channel.ErrNotExist, err)
+	}
+
+	if _, err := repo.Get(ctx, *user, first.UID); err != nil {
+		t.Errorf("want kept %s channel, got %v", first.UID, err)
+	}
+
+	if err := repo.Delete(ctx, *user, second.UID); !errors.Is(err, channel.ErrNotExist) {
+		t.Errorf("want %v for deleted channel, got %v", channel.ErrNotExist, err)
+	}
+
+	// new channel still goes last
+	if err := repo.Create(ctx, *user, *third); err != nil {
+		t.Fatal(err)
+	}
+
+	channels, err := repo.Fetch(ctx, *user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(channels) != 2 || channels[1].UID != third.UID || channels[1].Weight <= channels[0].Weight {
+		t.Errorf("want %s channel last, got %+v", third.UID, channels)
+	}
+}
+
+func testIsolation(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	owner := domain.TestUser(t)
+	stranger := &domain.User{URL: &url.URL{Scheme: "https", Host: "stranger.example.com", Path: "/"}}
+	c := domain.TestChannel(t)
+
+	if err := repo.Create(ctx, *owner, *c); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Get(ctx, *stranger, c.UID); !errors.Is(err, channel.ErrNotExist) {
+		t.Errorf("want %v for channel of another user, got %v", channel.ErrNotExist, err)
+	}
+
+	if err := repo.Delete(ctx, *stranger, c.UID); !errors.Is(err, channel.ErrNotExist) {
+		t.Errorf("want %v for channel of another user, got %v", channel.ErrNotExist, err)
+	}
+
+	channels, err := repo.Fetch(ctx, *stranger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(channels) != 0 {
+		t.Errorf("want no channels of another user, got %+v", channels)
+	}
+
+	// the same UID may be used by different users
+	if err = repo.Create(ctx, *stranger, *c); err != nil {
+		t.Error(err)
+	}
+}
+
+func testRetention(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	expect := domain.TestChannel(t)
+
+	if err := repo.Create(ctx, *user, *expect); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, policy := range []*domain.Retention{
+		{MaxAge: 72 * time.Hour, MaxCount: 100, KeepUnread: true},
+		{}, // own policy which keeps everything
+		nil,
+	} {
+		expect.Retention = policy
+
+		if err := repo.Update(ctx, *user, expect.UID, func(tx *domain.Channel) (*domain.Channel, error) {
+			tx.Retention = policy
+
+			return tx, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := repo.Get(ctx, *user, expect.UID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff(expect, actual, opts); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func testFetchAll(t *testing.T, repo channel.Repository) {
+	ctx := context.Background()
+	owner := domain.TestUser(t)
+	stranger := &domain.User{URL: &url.URL{Scheme: "https", Host: "stranger.example.com", Path: "/"}}
+	expect := map[string]string{
+		domain.TestChannel(t).UID: owner.String(),
+		domain.TestChannel(t).UID: owner.String(),
+		domain.TestChannel(t).UID: stranger.String(),
+	}
+
+	for uid, me := range expect {
+		u := owner
+		if me == stranger.String() {
+			u = stranger
+		}
+
+		if err := repo.Create(ctx, *u, domain.Channel{UID: uid, Name: uid}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	channels, err := repo.FetchAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make(map[string]string, len(channels))
+	for i := range channels {
+		actual[channels[i].Channel.UID] = channels[i].User.String()
+	}
+
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -16,6 +16,9 @@ type (
 		Fetch(ctx context.Context, user domain.User) ([]domain.Channel, error)
 		Update(ctx context.Context, user domain.User, uid string, update UpdateFunc) error
 		Delete(ctx context.Context, user domain.User, uid string) error
+
+		// FetchAll returns channels of all users.
+		FetchAll(ctx context.Context) ([]domain.UserChannel, error)
 	}
 )
 
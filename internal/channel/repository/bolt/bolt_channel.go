@@ -0,0 +1,305 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	database "source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	boltChannelRepository struct {
+		db *bolt.DB
+	}
+
+	// Channel is a stored value of channel.
+	Channel struct {
+		Retention *Retention `json:"retention,omitempty"`
+		UID       string     `json:"uid"`
+		Name      string     `json:"name"`
+		Unread    string     `json:"unread,omitempty"`
+		Weight    int        `json:"weight"`
+	}
+
+	// Retention is a stored retention policy of channel.
+	Retention struct {
+		MaxAge     time.Duration `json:"max_age,omitempty"`
+		MaxCount   int           `json:"max_count,omitempty"`
+		KeepUnread bool          `json:"keep_unread,omitempty"`
+	}
+)
+
+var (
+	// bucketChannels contains channels by their UID.
+	bucketChannels = []byte("channels")
+
+	// bucketOrder contains UIDs of channels prefixed by their weight, so
+	// cursor iterates them in order.
+	bucketOrder = []byte("channels_order")
+)
+
+// NewBoltChannelRepository creates channels repository in database opened by
+// bolt.Open.
+func NewBoltChannelRepository(db *bolt.DB) channel.Repository {
+	return &boltChannelRepository{
+		db: db,
+	}
+}
+
+func (repo *boltChannelRepository) Create(ctx context.Context, u domain.User, c domain.Channel) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		channels, order, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		if channels.Get([]byte(c.UID)) != nil {
+			return channel.ErrExist
+		}
+
+		// new channel goes last
+		c.Weight = 0
+		if last, _ := order.Cursor().Last(); last != nil {
+			c.Weight = int(database.ParseInt64Key(last)) + 1
+		}
+
+		return put(channels, order, c)
+	})
+}
+
+func (repo *boltChannelRepository) Get(ctx context.Context, u domain.User, cid string) (*domain.Channel, error) {
+	var out *domain.Channel
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		channels, _, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		out, err = get(channels, cid)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (repo *boltChannelRepository) Fetch(ctx context.Context, u domain.User) ([]domain.Channel, error) {
+	out := make([]domain.Channel, 0)
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		channels, order, err := buckets(tx, u)
+		if err != nil || order == nil {
+			return err
+		}
+
+		return order.ForEach(func(key, _ []byte) error {
+			c, err := get(channels, string(key[8:]))
+			if err != nil {
+				return err
+			}
+
+			out = append(out, *c)
+
+			return nil
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("cannot fetch channels: %w", err)
+	}
+
+	return out, nil
+}
+
+func (repo *boltChannelRepository) Update(ctx context.Context, u domain.User, cid string, update channel.UpdateFunc) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		channels, order, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		in, err := get(channels, cid)
+		if err != nil {
+			return fmt.Errorf("cannot find updating channel: %w", err)
+		}
+
+		if err = order.Delete(orderKey(*in)); err != nil {
+			return fmt.Errorf("cannot update channel order: %w", err)
+		}
+
+		out, err := update(in)
+		if err != nil {
+			// returned error rollbacks the whole transaction
+			return fmt.Errorf("cannot update channel: %w", err)
+		}
+
+		// channel cannot be moved to another UID by update
+		out.UID = cid
+
+		return put(channels, order, *out)
+	})
+}
+
+func (repo *boltChannelRepository) Delete(ctx context.Context, u domain.User, cid string) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		channels, order, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		c, err := get(channels, cid)
+		if err != nil {
+			return err
+		}
+
+		if err = order.Delete(orderKey(*c)); err != nil {
+			return fmt.Errorf("cannot delete channel order: %w", err)
+		}
+
+		if err = channels.Delete([]byte(cid)); err != nil {
+			return fmt.Errorf("cannot delete channel: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (repo *boltChannelRepository) FetchAll(ctx context.Context) ([]domain.UserChannel, error) {
+	out := make([]domain.UserChannel, 0)
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		return database.ForEachUser(tx, bucketChannels, func(me string, channels *bolt.Bucket) error {
+			u, err := url.Parse(me)
+			if err != nil {
+				return fmt.Errorf("cannot parse user of channel: %w", err)
+			}
+
+			return channels.ForEach(func(key, _ []byte) error {
+				c, err := get(channels, string(key))
+				if err != nil {
+					return err
+				}
+
+				out = append(out, domain.UserChannel{
+					User:    domain.User{URL: u},
+					Channel: *c,
+				})
+
+				return nil
+			})
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("cannot fetch channels of all users: %w", err)
+	}
+
+	return out, nil
+}
+
+func NewChannel(c domain.Channel) *Channel {
+	out := &Channel{
+		UID:    c.UID,
+		Name:   c.Name,
+		Weight: c.Weight,
+	}
+
+	if c.Unread != domain.UnreadModeUnd {
+		out.Unread = c.Unread.String()
+	}
+
+	if c.Retention != nil {
+		out.Retention = &Retention{
+			MaxAge:     c.Retention.MaxAge,
+			MaxCount:   c.Retention.MaxCount,
+			KeepUnread: c.Retention.KeepUnread,
+		}
+	}
+
+	return out
+}
+
+func (c Channel) Populate() *domain.Channel {
+	// channels without stored mode are shown with default one
+	mode, _ := domain.ParseUnreadMode(c.Unread)
+
+	out := &domain.Channel{
+		Unread: mode,
+		UID:    c.UID,
+		Name:   c.Name,
+		Weight: c.Weight,
+	}
+
+	if c.Retention != nil {
+		out.Retention = &domain.Retention{
+			MaxAge:     c.Retention.MaxAge,
+			MaxCount:   c.Retention.MaxCount,
+			KeepUnread: c.Retention.KeepUnread,
+		}
+	}
+
+	return out
+}
+
+// buckets returns channels and their order buckets of user. Both of them are
+// nil in read-only transaction if user has no channels yet.
+func buckets(tx *bolt.Tx, u domain.User) (*bolt.Bucket, *bolt.Bucket, error) {
+	channels, err := database.UserBucket(tx, u, bucketChannels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order, err := database.UserBucket(tx, u, bucketOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return channels, order, nil
+}
+
+func get(channels *bolt.Bucket, cid string) (*domain.Channel, error) {
+	if channels == nil {
+		return nil, channel.ErrNotExist
+	}
+
+	src := channels.Get([]byte(cid))
+	if src == nil {
+		return nil, channel.ErrNotExist
+	}
+
+	out := new(Channel)
+	if err := json.Unmarshal(src, out); err != nil {
+		return nil, fmt.Errorf("cannot decode channel: %w", err)
+	}
+
+	return out.Populate(), nil
+}
+
+func put(channels, order *bolt.Bucket, c domain.Channel) error {
+	src, err := json.Marshal(NewChannel(c))
+	if err != nil {
+		return fmt.Errorf("cannot encode channel: %w", err)
+	}
+
+	if err = channels.Put([]byte(c.UID), src); err != nil {
+		return fmt.Errorf("cannot store channel: %w", err)
+	}
+
+	if err = order.Put(orderKey(c), []byte{}); err != nil {
+		return fmt.Errorf("cannot store channel order: %w", err)
+	}
+
+	return nil
+}
+
+// orderKey returns key of channel in order bucket. Channels with the same
+// weight are ordered by UID.
+func orderKey(c domain.Channel) []byte {
+	return append(database.Int64Key(int64(c.Weight)), c.UID...)
+}
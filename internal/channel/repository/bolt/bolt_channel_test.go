@@ -0,0 +1,28 @@
+package bolt_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/channel/channeltest"
+	repository "source.toby3d.me/toby3d/sub/internal/channel/repository/bolt"
+	"source.toby3d.me/toby3d/sub/internal/database/bolt"
+)
+
+func TestBoltChannelRepository(t *testing.T) {
+	t.Parallel()
+
+	channeltest.TestRepository(t, func(tb testing.TB) channel.Repository {
+		tb.Helper()
+
+		db, err := bolt.Open(filepath.Join(tb.TempDir(), "sub.db"))
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		tb.Cleanup(func() { _ = db.Close() })
+
+		return repository.NewBoltChannelRepository(db)
+	})
+}
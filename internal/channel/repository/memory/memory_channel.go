@@ -2,8 +2,8 @@ package memory
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"net/url"
 	"sort"
 	"sync"
 
@@ -26,19 +26,21 @@ func NewMemoryChannelRepository() channel.Repository {
 }
 
 func (repo *memoryChannelRepository) Create(ctx context.Context, u domain.User, c domain.Channel) error {
-	result, err := repo.Get(ctx, u, c.UID)
-	if err != nil && !errors.Is(err, channel.ErrNotExist) {
-		return fmt.Errorf("cannot check creating channel: %w", err)
-	}
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
 
-	if result != nil {
+	if repo.index(u, c.UID) != -1 {
 		return channel.ErrExist
 	}
 
-	repo.mutex.Lock()
-	defer repo.mutex.Unlock()
+	// new channel goes last, even if some channels was deleted before
+	c.Weight = 0
+	for _, existing := range repo.channels[u.String()] {
+		if existing.Weight >= c.Weight {
+			c.Weight = existing.Weight + 1
+		}
+	}
 
-	c.Weight = len(repo.channels[u.String()])
 	repo.channels[u.String()] = append(repo.channels[u.String()], c)
 
 	return nil
@@ -48,64 +50,51 @@ func (repo *memoryChannelRepository) Get(ctx context.Context, u domain.User, cid
 	repo.mutex.RLock()
 	defer repo.mutex.RUnlock()
 
-	for uid, channels := range repo.channels {
-		if uid != u.String() {
-			continue
-		}
-
-		for j := range channels {
-			if channels[j].UID != cid {
-				continue
-			}
-
-			return &channels[j], nil
-		}
-
-		break
+	i := repo.index(u, cid)
+	if i == -1 {
+		return nil, channel.ErrNotExist
 	}
 
-	return nil, channel.ErrNotExist
+	// return a copy, so caller cannot change stored channel
+	out := repo.channels[u.String()][i]
+
+	return &out, nil
 }
 
 func (repo *memoryChannelRepository) Fetch(ctx context.Context, u domain.User) ([]domain.Channel, error) {
 	repo.mutex.RLock()
 	defer repo.mutex.RUnlock()
 
-	if out, ok := repo.channels[u.String()]; ok {
-		sort.Slice(out, func(i, j int) bool {
-			return out[i].Weight < out[j].Weight
-		})
+	out := make([]domain.Channel, len(repo.channels[u.String()]))
+	copy(out, repo.channels[u.String()])
 
-		return out, nil
-	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Weight < out[j].Weight
+	})
 
-	return nil, channel.ErrNotExist
+	return out, nil
 }
 
 func (repo *memoryChannelRepository) Update(ctx context.Context, u domain.User, cid string, update channel.UpdateFunc) error {
-	in, err := repo.Get(ctx, u, cid)
-	if err != nil {
-		return fmt.Errorf("cannot find updating channel: %w", err)
-	}
-
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
 
-	out, err := update(in)
-	if err != nil {
-		return fmt.Errorf("cannot update channel: %w", err)
+	i := repo.index(u, cid)
+	if i == -1 {
+		return fmt.Errorf("cannot find updating channel: %w", channel.ErrNotExist)
 	}
 
-	channels := repo.channels[u.String()]
-
-	for i := range channels {
-		if channels[i].UID != cid {
-			continue
-		}
+	in := repo.channels[u.String()][i]
 
-		repo.channels[u.String()][i] = *out
+	out, err := update(&in)
+	if err != nil {
+		return fmt.Errorf("cannot update channel: %w", err)
 	}
 
+	// channel cannot be moved to another UID by update
+	out.UID = cid
+	repo.channels[u.String()][i] = *out
+
 	return nil
 }
 
@@ -113,19 +102,49 @@ func (repo *memoryChannelRepository) Delete(ctx context.Context, u domain.User,
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
 
-	for uid, channels := range repo.channels {
-		if uid != u.String() {
-			continue
-		}
+	i := repo.index(u, cid)
+	if i == -1 {
+		return channel.ErrNotExist
+	}
+
+	repo.channels[u.String()] = slices.Delete(repo.channels[u.String()], i, i+1)
+
+	return nil
+}
+
+func (repo *memoryChannelRepository) FetchAll(ctx context.Context) ([]domain.UserChannel, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
 
-		for j := range channels {
-			repo.channels[uid] = slices.Delete(channels, j, j+1)
+	out := make([]domain.UserChannel, 0)
 
-			break
+	for me, channels := range repo.channels {
+		u, err := url.Parse(me)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse user of channel: %w", err)
 		}
 
-		break
+		for _, c := range channels {
+			out = append(out, domain.UserChannel{
+				User:    domain.User{URL: u},
+				Channel: c,
+			})
+		}
 	}
 
-	return nil
+	return out, nil
+}
+
+// index returns position of channel in the list of user channels, or -1 if
+// it's not exists. Caller must hold the mutex.
+func (repo *memoryChannelRepository) index(u domain.User, cid string) int {
+	channels := repo.channels[u.String()]
+
+	for i := range channels {
+		if channels[i].UID == cid {
+			return i
+		}
+	}
+
+	return -1
 }
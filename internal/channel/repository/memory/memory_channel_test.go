@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/channel/channeltest"
+	repository "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+)
+
+func TestMemoryChannelRepository(t *testing.T) {
+	t.Parallel()
+
+	channeltest.TestRepository(t, func(tb testing.TB) channel.Repository {
+		return repository.NewMemoryChannelRepository()
+	})
+}
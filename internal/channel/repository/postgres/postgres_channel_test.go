@@ -0,0 +1,18 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/channel/channeltest"
+	repository "source.toby3d.me/toby3d/sub/internal/channel/repository/postgres"
+	"source.toby3d.me/toby3d/sub/internal/database/postgres"
+)
+
+func TestPostgresChannelRepository(t *testing.T) {
+	t.Parallel()
+
+	channeltest.TestRepository(t, func(tb testing.TB) channel.Repository {
+		return repository.NewPostgresChannelRepository(postgres.TestDatabase(tb))
+	})
+}
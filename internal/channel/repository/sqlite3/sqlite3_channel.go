@@ -0,0 +1,224 @@
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	sqlite3ChannelRepository struct {
+		db *sqlx.DB
+	}
+
+	// Channel is a row of channels table.
+	Channel struct {
+		Me                  string        `db:"me"`
+		UID                 string        `db:"uid"`
+		Name                string        `db:"name"`
+		Unread              string        `db:"unread"`
+		RetentionMaxAge     sql.NullInt64 `db:"retention_max_age"`
+		RetentionMaxCount   sql.NullInt64 `db:"retention_max_count"`
+		RetentionKeepUnread sql.NullBool  `db:"retention_keep_unread"`
+		Weight              int           `db:"weight"`
+	}
+)
+
+const (
+	queryTable string = `SELECT me, uid, name, unread, weight, retention_max_age, retention_max_count,
+		retention_keep_unread FROM channels`
+	queryGet      string = queryTable + " WHERE me = ? AND uid = ?;"
+	queryFetch    string = queryTable + " WHERE me = ? ORDER BY weight, rowid;"
+	queryFetchAll string = queryTable + " ORDER BY me, weight, rowid;"
+	queryCreate   string = `INSERT INTO channels (me, uid, name, unread, weight, retention_max_age,
+			retention_max_count, retention_keep_unread)
+		SELECT :me, :uid, :name, :unread, COALESCE(MAX(weight) + 1, 0), :retention_max_age,
+			:retention_max_count, :retention_keep_unread FROM channels WHERE me = :me;`
+	queryUpdate string = `UPDATE channels SET name = :name, unread = :unread, weight = :weight,
+			retention_max_age = :retention_max_age, retention_max_count = :retention_max_count,
+			retention_keep_unread = :retention_keep_unread
+		WHERE me = :me AND uid = :uid;`
+	queryDelete string = "DELETE FROM channels WHERE me = ? AND uid = ?;"
+)
+
+// NewSQLite3ChannelRepository creates channels repository in database opened
+// by sqlite3.Open.
+func NewSQLite3ChannelRepository(db *sqlx.DB) channel.Repository {
+	return &sqlite3ChannelRepository{
+		db: db,
+	}
+}
+
+func (repo *sqlite3ChannelRepository) Create(ctx context.Context, u domain.User, c domain.Channel) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = get(ctx, tx, u, c.UID); err == nil {
+		return channel.ErrExist
+	} else if !errors.Is(err, channel.ErrNotExist) {
+		return fmt.Errorf("cannot check creating channel: %w", err)
+	}
+
+	if _, err = tx.NamedExecContext(ctx, queryCreate, NewChannel(u, c)); err != nil {
+		return fmt.Errorf("cannot create channel: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("cannot commit created channel: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *sqlite3ChannelRepository) Get(ctx context.Context, u domain.User, cid string) (*domain.Channel, error) {
+	return get(ctx, repo.db, u, cid)
+}
+
+func (repo *sqlite3ChannelRepository) Fetch(ctx context.Context, u domain.User) ([]domain.Channel, error) {
+	rows := make([]Channel, 0)
+	if err := repo.db.SelectContext(ctx, &rows, queryFetch, u.String()); err != nil {
+		return nil, fmt.Errorf("cannot fetch channels: %w", err)
+	}
+
+	out := make([]domain.Channel, 0, len(rows))
+	for i := range rows {
+		out = append(out, *rows[i].Populate())
+	}
+
+	return out, nil
+}
+
+func (repo *sqlite3ChannelRepository) Update(ctx context.Context, u domain.User, cid string, update channel.UpdateFunc) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	in, err := get(ctx, tx, u, cid)
+	if err != nil {
+		return fmt.Errorf("cannot find updating channel: %w", err)
+	}
+
+	out, err := update(in)
+	if err != nil {
+		return fmt.Errorf("cannot update channel: %w", err)
+	}
+
+	// channel cannot be moved to another UID by update
+	out.UID = cid
+
+	if _, err = tx.NamedExecContext(ctx, queryUpdate, NewChannel(u, *out)); err != nil {
+		return fmt.Errorf("cannot update channel: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("cannot commit updated channel: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *sqlite3ChannelRepository) Delete(ctx context.Context, u domain.User, cid string) error {
+	result, err := repo.db.ExecContext(ctx, queryDelete, u.String(), cid)
+	if err != nil {
+		return fmt.Errorf("cannot delete channel: %w", err)
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return channel.ErrNotExist
+	}
+
+	return nil
+}
+
+func (repo *sqlite3ChannelRepository) FetchAll(ctx context.Context) ([]domain.UserChannel, error) {
+	rows := make([]Channel, 0)
+	if err := repo.db.SelectContext(ctx, &rows, queryFetchAll); err != nil {
+		return nil, fmt.Errorf("cannot fetch channels of all users: %w", err)
+	}
+
+	out := make([]domain.UserChannel, 0, len(rows))
+
+	for i := range rows {
+		u, err := url.Parse(rows[i].Me)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse user of channel: %w", err)
+		}
+
+		out = append(out, domain.UserChannel{
+			User:    domain.User{URL: u},
+			Channel: *rows[i].Populate(),
+		})
+	}
+
+	return out, nil
+}
+
+func NewChannel(u domain.User, c domain.Channel) *Channel {
+	out := &Channel{
+		Me:     u.String(),
+		UID:    c.UID,
+		Name:   c.Name,
+		Weight: c.Weight,
+	}
+
+	if c.Unread != domain.UnreadModeUnd {
+		out.Unread = c.Unread.String()
+	}
+
+	if c.Retention != nil {
+		out.RetentionMaxAge = sql.NullInt64{Int64: int64(c.Retention.MaxAge), Valid: true}
+		out.RetentionMaxCount = sql.NullInt64{Int64: int64(c.Retention.MaxCount), Valid: true}
+		out.RetentionKeepUnread = sql.NullBool{Bool: c.Retention.KeepUnread, Valid: true}
+	}
+
+	return out
+}
+
+func (c Channel) Populate() *domain.Channel {
+	// channels without stored mode are shown with default one
+	mode, _ := domain.ParseUnreadMode(c.Unread)
+
+	out := &domain.Channel{
+		Unread: mode,
+		UID:    c.UID,
+		Name:   c.Name,
+		Weight: c.Weight,
+	}
+
+	// policy columns are stored together, so any of them marks own policy
+	if c.RetentionMaxAge.Valid {
+		out.Retention = &domain.Retention{
+			MaxAge:     time.Duration(c.RetentionMaxAge.Int64),
+			MaxCount:   int(c.RetentionMaxCount.Int64),
+			KeepUnread: c.RetentionKeepUnread.Bool,
+		}
+	}
+
+	return out
+}
+
+func get(ctx context.Context, db sqlx.QueryerContext, u domain.User, cid string) (*domain.Channel, error) {
+	row := new(Channel)
+	if err := sqlx.GetContext(ctx, db, row, queryGet, u.String(), cid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, channel.ErrNotExist
+		}
+
+		return nil, fmt.Errorf("cannot get channel: %w", err)
+	}
+
+	return row.Populate(), nil
+}
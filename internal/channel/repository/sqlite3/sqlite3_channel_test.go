@@ -0,0 +1,28 @@
+package sqlite3_test
+
+import (
+	"context"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/channel/channeltest"
+	repository "source.toby3d.me/toby3d/sub/internal/channel/repository/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/database/sqlite3"
+)
+
+func TestSQLite3ChannelRepository(t *testing.T) {
+	t.Parallel()
+
+	channeltest.TestRepository(t, func(tb testing.TB) channel.Repository {
+		tb.Helper()
+
+		db, err := sqlite3.Open(context.Background(), ":memory:")
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		tb.Cleanup(func() { _ = db.Close() })
+
+		return repository.NewSQLite3ChannelRepository(db)
+	})
+}
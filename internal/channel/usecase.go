@@ -12,8 +12,21 @@ type UseCase interface {
 	Fetch(ctx context.Context, u domain.User) ([]domain.Channel, error)
 	Create(ctx context.Context, u domain.User, name string) (*domain.Channel, error)
 	Update(ctx context.Context, u domain.User, uid, name string) (*domain.Channel, error)
+
+	// SetUnread changes the mode of showing unread entries in channel.
+	// Notifications channel always shows the count.
+	SetUnread(ctx context.Context, u domain.User, uid string, mode domain.UnreadMode) (*domain.Channel, error)
+
+	// SetRetention changes own retention policy of channel, nil policy
+	// resets channel to the default one. Notifications channel without
+	// own policy is compacted by the default one only if it's enabled for
+	// notifications.
+	SetRetention(ctx context.Context, u domain.User, uid string, policy *domain.Retention) (*domain.Channel, error)
 	Order(ctx context.Context, u domain.User, uids []string) error
 	Delete(ctx context.Context, u domain.User, uid string) error
 }
 
-var ErrNotifications = errors.New(common.ChannelNotifications + " channel cannot be deleted")
+var (
+	ErrNotifications       = errors.New(common.ChannelNotifications + " channel cannot be renamed or deleted")
+	ErrNotificationsUnread = errors.New("unread mode of " + common.ChannelNotifications + " channel cannot be changed")
+)
@@ -3,17 +3,47 @@ package usecase
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/rand"
 
 	"source.toby3d.me/toby3d/sub/internal/channel"
 	"source.toby3d.me/toby3d/sub/internal/common"
 	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/event"
+	eventmemory "source.toby3d.me/toby3d/sub/internal/event/memory"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	followmemoryrepo "source.toby3d.me/toby3d/sub/internal/follow/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
 )
 
 type (
 	channelUseCase struct {
 		channels channel.Repository
+		follows  follow.Repository
+		entries  timeline.Repository
+		users    user.Repository
+		events   event.Bus
+	}
+
+	NewChannelUseCaseOptions struct {
+		Channels channel.Repository
+
+		// Follows and Entries contain subscriptions and entries of
+		// channels, which are deleted together with channel. Channels
+		// have nothing to delete if nil.
+		Follows follow.Repository
+		Entries timeline.Repository
+
+		// Users keeps settings of notifications channels, which are not
+		// stored as channels. In-memory registry is used if nil.
+		Users user.Repository
+
+		// Events receives channels changes. Events are discarded if nil.
+		Events event.Bus
 	}
 
 	orderItem struct {
@@ -22,10 +52,32 @@ type (
 	}
 )
 
-func NewChannelUseCase(channels channel.Repository) channel.UseCase {
-	return &channelUseCase{
-		channels: channels,
+func NewChannelUseCase(opts NewChannelUseCaseOptions) channel.UseCase {
+	out := &channelUseCase{
+		channels: opts.Channels,
+		follows:  opts.Follows,
+		entries:  opts.Entries,
+		users:    opts.Users,
+		events:   opts.Events,
+	}
+
+	if out.follows == nil {
+		out.follows = followmemoryrepo.NewMemoryFollowRepository()
+	}
+
+	if out.entries == nil {
+		out.entries = timelinememoryrepo.NewMemoryTimelineRepository()
+	}
+
+	if out.users == nil {
+		out.users = usermemoryrepo.NewMemoryUserRepository()
+	}
+
+	if out.events == nil {
+		out.events = eventmemory.NewMemoryEventBus()
 	}
+
+	return out
 }
 
 func (ucase *channelUseCase) Fetch(ctx context.Context, u domain.User) ([]domain.Channel, error) {
@@ -34,11 +86,12 @@ func (ucase *channelUseCase) Fetch(ctx context.Context, u domain.User) ([]domain
 		return nil, fmt.Errorf("cannot fetch channels: %w", err)
 	}
 
-	return append([]domain.Channel{{
-		UID:    common.ChannelNotifications,
-		Name:   "Notifications",
-		Weight: -1,
-	}}, channels...), nil
+	policy, err := ucase.users.GetRetention(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get notifications retention policy: %w", err)
+	}
+
+	return append([]domain.Channel{notifications(policy)}, channels...), nil
 }
 
 func (ucase *channelUseCase) Create(ctx context.Context, u domain.User, name string) (*domain.Channel, error) {
@@ -60,10 +113,16 @@ func (ucase *channelUseCase) Create(ctx context.Context, u domain.User, name str
 		return nil, fmt.Errorf("cannot return created channel: %w", err)
 	}
 
+	ucase.events.Publish(ctx, u, domain.Event{Type: domain.EventTypeChannelCreated, Channel: *out})
+
 	return out, nil
 }
 
 func (ucase *channelUseCase) Update(ctx context.Context, u domain.User, uid, name string) (*domain.Channel, error) {
+	if uid == common.ChannelNotifications {
+		return nil, channel.ErrNotifications
+	}
+
 	if err := ucase.channels.Update(ctx, u, uid, func(tx *domain.Channel) (*domain.Channel, error) {
 		tx.Name = name
 
@@ -77,6 +136,65 @@ func (ucase *channelUseCase) Update(ctx context.Context, u domain.User, uid, nam
 		return nil, fmt.Errorf("cannot return updated channel: %w", err)
 	}
 
+	ucase.events.Publish(ctx, u, domain.Event{Type: domain.EventTypeChannelChanged, Channel: *out})
+
+	return out, nil
+}
+
+func (ucase *channelUseCase) SetUnread(ctx context.Context, u domain.User, uid string, mode domain.UnreadMode) (
+	*domain.Channel, error,
+) {
+	if uid == common.ChannelNotifications {
+		return nil, channel.ErrNotificationsUnread
+	}
+
+	if err := ucase.channels.Update(ctx, u, uid, func(tx *domain.Channel) (*domain.Channel, error) {
+		tx.Unread = mode
+
+		return tx, nil
+	}); err != nil {
+		return nil, fmt.Errorf("cannot update channel unread mode: %w", err)
+	}
+
+	out, err := ucase.channels.Get(ctx, u, uid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot return updated channel: %w", err)
+	}
+
+	ucase.events.Publish(ctx, u, domain.Event{Type: domain.EventTypeChannelChanged, Channel: *out})
+
+	return out, nil
+}
+
+func (ucase *channelUseCase) SetRetention(ctx context.Context, u domain.User, uid string, policy *domain.Retention) (
+	*domain.Channel, error,
+) {
+	if uid == common.ChannelNotifications {
+		if err := ucase.users.SetRetention(ctx, u, policy); err != nil {
+			return nil, fmt.Errorf("cannot update notifications retention policy: %w", err)
+		}
+
+		out := notifications(policy)
+		ucase.events.Publish(ctx, u, domain.Event{Type: domain.EventTypeChannelChanged, Channel: out})
+
+		return &out, nil
+	}
+
+	if err := ucase.channels.Update(ctx, u, uid, func(tx *domain.Channel) (*domain.Channel, error) {
+		tx.Retention = policy
+
+		return tx, nil
+	}); err != nil {
+		return nil, fmt.Errorf("cannot update channel retention policy: %w", err)
+	}
+
+	out, err := ucase.channels.Get(ctx, u, uid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot return updated channel: %w", err)
+	}
+
+	ucase.events.Publish(ctx, u, domain.Event{Type: domain.EventTypeChannelChanged, Channel: *out})
+
 	return out, nil
 }
 
@@ -126,9 +244,51 @@ func (ucase *channelUseCase) Delete(ctx context.Context, u domain.User, uid stri
 		return channel.ErrNotifications
 	}
 
-	if err := ucase.channels.Delete(ctx, u, uid); err != nil {
+	// channel goes last, so failed deletion can be repeated and nothing is
+	// fetched into channel which is already deleted
+	follows, err := ucase.follows.Fetch(ctx, u, uid)
+	if err != nil {
+		return fmt.Errorf("cannot fetch channel follows: %w", err)
+	}
+
+	for i := range follows {
+		if err = ucase.follows.Delete(ctx, u, uid, follows[i].URL); err != nil &&
+			!errors.Is(err, follow.ErrNotExist) {
+			return fmt.Errorf("cannot delete channel follow: %w", err)
+		}
+	}
+
+	entries, err := ucase.entries.Fetch(ctx, u, uid)
+	if err != nil {
+		return fmt.Errorf("cannot fetch channel entries: %w", err)
+	}
+
+	for i := range entries {
+		if err = ucase.entries.Delete(ctx, u, entries[i].ID); err != nil && !errors.Is(err, timeline.ErrNotExist) {
+			return fmt.Errorf("cannot delete channel entry: %w", err)
+		}
+	}
+
+	if err = ucase.channels.Delete(ctx, u, uid); err != nil {
 		return fmt.Errorf("cannot delete channel: %w", err)
 	}
 
+	ucase.events.Publish(ctx, u, domain.Event{
+		Type:    domain.EventTypeChannelDeleted,
+		Channel: domain.Channel{UID: uid},
+	})
+
 	return nil
 }
+
+// notifications returns the channel of notifications, which is shown to every
+// user even if it's not stored.
+func notifications(policy *domain.Retention) domain.Channel {
+	return domain.Channel{
+		Unread:    domain.UnreadModeCount,
+		Retention: policy,
+		UID:       common.ChannelNotifications,
+		Name:      "Notifications",
+		Weight:    -1,
+	}
+}
@@ -2,14 +2,19 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	"source.toby3d.me/toby3d/sub/internal/channel"
 	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
 	ucase "source.toby3d.me/toby3d/sub/internal/channel/usecase"
 	"source.toby3d.me/toby3d/sub/internal/common"
 	"source.toby3d.me/toby3d/sub/internal/domain"
+	eventmemory "source.toby3d.me/toby3d/sub/internal/event/memory"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
 )
 
 func TestChannelUseCase_Create(t *testing.T) {
@@ -18,7 +23,11 @@ func TestChannelUseCase_Create(t *testing.T) {
 	user := domain.TestUser(t)
 	channels := channelmemoryrepo.NewMemoryChannelRepository()
 
-	actual, err := ucase.NewChannelUseCase(channels).Create(context.Background(), *user, "Testing")
+	actual, err := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: channels,
+		Events:   eventmemory.NewMemoryEventBus(),
+	}).
+		Create(context.Background(), *user, "Testing")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -43,7 +52,10 @@ func TestChannelUseCase_Update(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	actual, err := ucase.NewChannelUseCase(channels).
+	actual, err := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: channels,
+		Events:   eventmemory.NewMemoryEventBus(),
+	}).
 		Update(context.Background(), *user, channel.UID, "Testing")
 	if err != nil {
 		t.Fatal(err)
@@ -70,7 +82,10 @@ func TestChannelUseCase_Order(t *testing.T) {
 		}
 	}
 
-	if err := ucase.NewChannelUseCase(channels).
+	if err := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: channels,
+		Events:   eventmemory.NewMemoryEventBus(),
+	}).
 		Order(context.Background(), *user, []string{"d", "a", "c", "g"}); err != nil {
 		t.Fatal(err)
 	}
@@ -98,7 +113,7 @@ func TestChannelUseCase_Fetch(t *testing.T) {
 	user := domain.TestUser(t)
 	channels := channelmemoryrepo.NewMemoryChannelRepository()
 	expect := []domain.Channel{
-		{UID: common.ChannelNotifications, Name: "Notifications", Weight: -1},
+		{Unread: domain.UnreadModeCount, UID: common.ChannelNotifications, Name: "Notifications", Weight: -1},
 		*domain.TestChannel(t),
 		*domain.TestChannel(t),
 	}
@@ -110,12 +125,16 @@ func TestChannelUseCase_Fetch(t *testing.T) {
 		}
 	}
 
-	actual, err := ucase.NewChannelUseCase(channels).Fetch(context.Background(), *user)
+	actual, err := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: channels,
+		Events:   eventmemory.NewMemoryEventBus(),
+	}).
+		Fetch(context.Background(), *user)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if diff := cmp.Diff(actual, expect); diff != "" {
+	if diff := cmp.Diff(actual, expect, cmp.AllowUnexported(domain.UnreadMode{})); diff != "" {
 		t.Error(diff)
 	}
 }
@@ -131,8 +150,118 @@ func TestChannelUseCase_Delete(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := ucase.NewChannelUseCase(channels).
+	if err := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: channels,
+		Events:   eventmemory.NewMemoryEventBus(),
+	}).
 		Delete(context.Background(), *user, channel.UID); err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestChannelUseCase_SetRetention(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	repo := channelmemoryrepo.NewMemoryChannelRepository()
+	channels := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: repo,
+		Events:   eventmemory.NewMemoryEventBus(),
+	})
+	expect := &domain.Retention{MaxAge: time.Hour, MaxCount: 10, KeepUnread: true}
+
+	c := domain.TestChannel(t)
+	if err := repo.Create(context.Background(), *user, *c); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := channels.SetRetention(context.Background(), *user, c.UID, expect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expect, actual.Retention); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestChannelUseCase_SetRetention_Notifications(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	repo := channelmemoryrepo.NewMemoryChannelRepository()
+	users := usermemoryrepo.NewMemoryUserRepository()
+	channels := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: repo,
+		Users:    users,
+		Events:   eventmemory.NewMemoryEventBus(),
+	})
+	expect := &domain.Retention{MaxAge: time.Hour}
+
+	c := domain.TestChannel(t)
+	if err := repo.Create(ctx, *user, *c); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, policy := range []*domain.Retention{expect, nil, expect} {
+		actual, err := channels.SetRetention(ctx, *user, common.ChannelNotifications, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual.UID != common.ChannelNotifications || actual.Name != "Notifications" {
+			t.Errorf("expect notifications channel, got %+v", actual)
+		}
+
+		if diff := cmp.Diff(policy, actual.Retention); diff != "" {
+			t.Error(diff)
+		}
+	}
+
+	// policy is kept by user, notifications channel is not stored
+	policy, err := users.GetRetention(ctx, *user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expect, policy); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err = repo.Get(ctx, *user, common.ChannelNotifications); !errors.Is(err, channel.ErrNotExist) {
+		t.Errorf("expect %v for stored notifications channel, got %v", channel.ErrNotExist, err)
+	}
+
+	result, err := channels.Fetch(ctx, *user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 2 || !result[0].IsNotifications() || result[1].UID != c.UID {
+		t.Fatalf("expect notifications and %s channels, got %+v", c.UID, result)
+	}
+
+	if diff := cmp.Diff(expect, result[0].Retention); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err = channels.Update(ctx, *user, common.ChannelNotifications, "Renamed"); !errors.Is(err,
+		channel.ErrNotifications) {
+		t.Errorf("expect %v for renaming notifications channel, got %v", channel.ErrNotifications, err)
+	}
+}
+
+func TestChannelUseCase_SetUnread_Notifications(t *testing.T) {
+	t.Parallel()
+
+	channels := ucase.NewChannelUseCase(ucase.NewChannelUseCaseOptions{
+		Channels: channelmemoryrepo.NewMemoryChannelRepository(),
+		Events:   eventmemory.NewMemoryEventBus(),
+	})
+
+	if _, err := channels.SetUnread(context.Background(), *domain.TestUser(t), common.ChannelNotifications,
+		domain.UnreadModeOff); !errors.Is(err, channel.ErrNotificationsUnread) {
+		t.Errorf("expect %v for notifications channel, got %v", channel.ErrNotificationsUnread, err)
+	}
+}
@@ -1,7 +1,8 @@
 package common
 
 const (
-	HeaderContentType = "Content-Type"
+	HeaderCacheControl = "Cache-Control"
+	HeaderContentType  = "Content-Type"
 )
 
 const (
@@ -9,6 +10,7 @@ const (
 	MIMEApplicationJSONCharsetUTF8 = MIMEApplicationJSON + "; " + charsetUTF8
 	MIMEApplicationForm            = "application/x-www-form-urlencoded"
 	MIMEApplicationFormCharsetUTF8 = MIMEApplicationForm + "; " + charsetUTF8
+	MIMETextEventStream            = "text/event-stream"
 	charsetUTF8                    = "charset=UTF-8"
 )
 
@@ -0,0 +1,146 @@
+// Package compactor periodically removes old entries from channels by their
+// retention policies.
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
+)
+
+type (
+	Compactor struct {
+		channels      channel.Repository
+		users         user.Repository
+		timelines     timeline.UseCase
+		logger        *log.Logger
+		policy        domain.Retention
+		notifications bool
+	}
+
+	NewCompactorOptions struct {
+		Channels channel.Repository
+
+		// Users contains owners of notifications channels and their
+		// policies, because these channels are not stored. In-memory
+		// registry is used if nil.
+		Users     user.Repository
+		Timelines timeline.UseCase
+		Logger    *log.Logger
+
+		// Policy is a default retention policy of channels without own
+		// one. Zero policy keeps entries of such channels forever.
+		Policy domain.Retention
+
+		// Notifications applies default policy to notifications channels
+		// without own one, which are exempt from compaction otherwise.
+		Notifications bool
+	}
+)
+
+func NewCompactor(opts NewCompactorOptions) *Compactor {
+	out := &Compactor{
+		channels:      opts.Channels,
+		users:         opts.Users,
+		timelines:     opts.Timelines,
+		logger:        opts.Logger,
+		policy:        opts.Policy,
+		notifications: opts.Notifications,
+	}
+
+	if out.users == nil {
+		out.users = usermemoryrepo.NewMemoryUserRepository()
+	}
+
+	if out.logger == nil {
+		out.logger = log.New(io.Discard, "", 0)
+	}
+
+	return out
+}
+
+// Run compacts all channels on every tick until ctx is done.
+func (c *Compactor) Run(ctx context.Context, tick time.Duration) error {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Compact(ctx, time.Now()); err != nil {
+			c.logger.Println("cannot compact channels:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compact removes entries which are out of retention policies at the moment
+// now from channels of all users. Failure of a single channel does not stop
+// compaction of others.
+func (c *Compactor) Compact(ctx context.Context, now time.Time) error {
+	channels, err := c.channels.FetchAll(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot fetch channels: %w", err)
+	}
+
+	users, err := c.users.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot fetch users: %w", err)
+	}
+
+	// notifications channel is not stored, so it's compacted for every
+	// known user
+	for i := range users {
+		policy, err := c.users.GetRetention(ctx, users[i])
+		if err != nil {
+			c.logger.Printf("cannot get notifications retention policy of %s: %s", users[i], err)
+
+			continue
+		}
+
+		if policy == nil && !c.notifications {
+			continue
+		}
+
+		channels = append(channels, domain.UserChannel{
+			User:    users[i],
+			Channel: domain.Channel{UID: common.ChannelNotifications, Retention: policy},
+		})
+	}
+
+	for _, uc := range channels {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		policy := c.policy
+		if uc.Channel.Retention != nil {
+			policy = *uc.Channel.Retention
+		}
+
+		count, err := c.timelines.Compact(ctx, uc.User, uc.Channel.UID, policy, now)
+		if err != nil {
+			c.logger.Printf("cannot compact %s channel of %s: %s", uc.Channel.UID, uc.User, err)
+
+			continue
+		}
+
+		if count > 0 {
+			c.logger.Printf("compacted %d entries of %s channel of %s", count, uc.Channel.UID, uc.User)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package compactor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/compactor"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+	timelineucase "source.toby3d.me/toby3d/sub/internal/timeline/usecase"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
+)
+
+func TestCompactor_Compact(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		policy        *domain.Retention
+		notifications bool
+		expect        map[string]int
+	}{
+		"exempt notifications": {
+			expect: map[string]int{"own": 1, "default": 2, common.ChannelNotifications: 5},
+		},
+		"notifications": {
+			notifications: true,
+			expect:        map[string]int{"own": 1, "default": 2, common.ChannelNotifications: 2},
+		},
+		"own notifications": {
+			policy: &domain.Retention{MaxCount: 3},
+			expect: map[string]int{"own": 1, "default": 2, common.ChannelNotifications: 3},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			user := domain.TestUser(t)
+			channels := channelmemoryrepo.NewMemoryChannelRepository()
+			timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+				Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+			})
+
+			users := usermemoryrepo.NewMemoryUserRepository()
+			if err := users.Create(ctx, *user); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := users.SetRetention(ctx, *user, tc.policy); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, c := range []domain.Channel{
+				{UID: "own", Name: "Own", Retention: &domain.Retention{MaxCount: 1}},
+				{UID: "default", Name: "Default"},
+			} {
+				if err := channels.Create(ctx, *user, c); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for uid := range tc.expect {
+				for i := 0; i < 5; i++ {
+					if _, err := timelines.Create(ctx, *user, uid, *domain.TestEntry(t)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			if err := compactor.NewCompactor(compactor.NewCompactorOptions{
+				Channels:      channels,
+				Users:         users,
+				Timelines:     timelines,
+				Policy:        domain.Retention{MaxCount: 2},
+				Notifications: tc.notifications,
+			}).Compact(ctx, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+
+			for uid, expect := range tc.expect {
+				result, err := timelines.Fetch(ctx, *user, uid, domain.Paging{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(result.Items) != expect {
+					t.Errorf("expect %d entries in %s channel, got %d", expect, uid, len(result.Items))
+				}
+			}
+		})
+	}
+}
+
+func TestCompactor_Compact_WithoutChannels(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+
+	// user receives webmentions, but has no own channels
+	users := usermemoryrepo.NewMemoryUserRepository()
+	if err := users.Create(ctx, *user); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := timelines.Create(ctx, *user, common.ChannelNotifications, *domain.TestEntry(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := compactor.NewCompactor(compactor.NewCompactorOptions{
+		Channels:      channelmemoryrepo.NewMemoryChannelRepository(),
+		Users:         users,
+		Timelines:     timelines,
+		Policy:        domain.Retention{MaxCount: 2},
+		Notifications: true,
+	}).Compact(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := timelines.Fetch(ctx, *user, common.ChannelNotifications, domain.Paging{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Items) != 2 {
+		t.Errorf("expect %d notifications, got %d", 2, len(result.Items))
+	}
+}
@@ -0,0 +1,352 @@
+// Package config loads application settings from configuration file,
+// environment variables and command line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	Config struct {
+		Server    Server    `toml:"server" yaml:"server"`
+		Storage   Storage   `toml:"storage" yaml:"storage"`
+		Auth      Auth      `toml:"auth" yaml:"auth"`
+		Fetcher   Fetcher   `toml:"fetcher" yaml:"fetcher"`
+		Retention Retention `toml:"retention" yaml:"retention"`
+		Limits    Limits    `toml:"limits" yaml:"limits"`
+	}
+
+	Server struct {
+		// Addr is a TCP address to listen on.
+		Addr string `toml:"addr" yaml:"addr"`
+
+		// BaseURL is a public root URL of the server, used to build
+		// absolute links to endpoints.
+		BaseURL string `toml:"base_url" yaml:"base_url"`
+	}
+
+	Storage struct {
+		// Backend is a name of storage implementation.
+		Backend string `toml:"backend" yaml:"backend"`
+
+		// DSN is a data source name of storage, its format depends on
+		// backend.
+		DSN string `toml:"dsn" yaml:"dsn"`
+	}
+
+	Auth struct {
+		// TokenEndpoint is an IndieAuth token endpoint which verifies
+		// access tokens.
+		TokenEndpoint string `toml:"token_endpoint" yaml:"token_endpoint"`
+	}
+
+	Fetcher struct {
+		// Interval is a delay between successful fetches of the same
+		// feed.
+		Interval Duration `toml:"interval" yaml:"interval"`
+
+		// MaxBackoff limits delay between fetches of failing feed.
+		MaxBackoff Duration `toml:"max_backoff" yaml:"max_backoff"`
+
+		// Tick is a delay between checks for feeds to fetch.
+		Tick Duration `toml:"tick" yaml:"tick"`
+	}
+
+	// Retention is a default policy of removing old entries from channels
+	// without own one.
+	Retention struct {
+		// MaxAge removes entries older than this duration, zero keeps
+		// them forever.
+		MaxAge Duration `toml:"max_age" yaml:"max_age"`
+
+		// MaxCount removes entries beyond this count of the newest ones,
+		// zero keeps all of them.
+		MaxCount int `toml:"max_count" yaml:"max_count"`
+
+		// KeepUnread protects unread entries from removal.
+		KeepUnread bool `toml:"keep_unread" yaml:"keep_unread"`
+
+		// Notifications applies policy to notifications channel without
+		// own one, which is exempt otherwise.
+		Notifications bool `toml:"notifications" yaml:"notifications"`
+
+		// Interval is a delay between compactions of all channels.
+		Interval Duration `toml:"interval" yaml:"interval"`
+	}
+
+	Limits struct {
+		// Workers is a maximum number of concurrent feed fetches.
+		Workers int `toml:"workers" yaml:"workers"`
+
+		// Timeout limits time of outgoing HTTP requests.
+		Timeout Duration `toml:"timeout" yaml:"timeout"`
+
+		// AllowPrivate lets fetching of feeds and searching reach
+		// loopback and private networks, which are refused by default.
+		AllowPrivate bool `toml:"allow_private" yaml:"allow_private"`
+
+		// TokenTTL is a time of caching verified access tokens.
+		TokenTTL Duration `toml:"token_ttl" yaml:"token_ttl"`
+
+		// Heartbeat is an interval of keepalive comments in events
+		// stream.
+		Heartbeat Duration `toml:"heartbeat" yaml:"heartbeat"`
+	}
+
+	// Duration is a time.Duration which is decoded from strings like
+	// '15m' or '1h30m'.
+	Duration struct {
+		time.Duration
+	}
+)
+
+// EnvPrefix is a prefix of environment variables which override
+// configuration file values, like SUB_SERVER_ADDR.
+const EnvPrefix string = "SUB_"
+
+// Supported storage backends.
+const (
+	StorageBolt     string = "bolt"
+	StorageMemory   string = "memory"
+	StoragePostgres string = "postgres"
+	StorageSQLite3  string = "sqlite3"
+)
+
+var (
+	ErrFormat = errors.New("unsupported configuration file format")
+	ErrConfig = errors.New("invalid configuration")
+)
+
+// Default returns configuration used for values which are not provided.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr: ":3000",
+		},
+		Storage: Storage{
+			Backend: StorageMemory,
+		},
+		Fetcher: Fetcher{
+			Interval:   Duration{15 * time.Minute},
+			MaxBackoff: Duration{24 * time.Hour},
+			Tick:       Duration{time.Minute},
+		},
+		Retention: Retention{
+			KeepUnread: true,
+			Interval:   Duration{time.Hour},
+		},
+		Limits: Limits{
+			Workers:   4,
+			Timeout:   Duration{30 * time.Second},
+			TokenTTL:  Duration{5 * time.Minute},
+			Heartbeat: Duration{30 * time.Second},
+		},
+	}
+}
+
+// Load reads configuration file by path on top of defaults and applies
+// environment overrides from env in the 'KEY=value' form. File is optional if
+// path is empty. Result is not validated.
+func Load(path string, env []string) (*Config, error) {
+	out := Default()
+
+	if path != "" {
+		if err := out.decodeFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := out.applyEnv(env); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Validate reports all invalid values of configuration in a single error.
+func (c Config) Validate() error {
+	errs := make([]string, 0)
+
+	if c.Server.Addr == "" {
+		errs = append(errs, "server.addr must be provided")
+	}
+
+	if c.Server.BaseURL != "" && !isHTTPURL(c.Server.BaseURL) {
+		errs = append(errs, "server.base_url must be an absolute http or https URL")
+	}
+
+	switch c.Storage.Backend {
+	default:
+		errs = append(errs, fmt.Sprintf("storage.backend '%s' is not supported", c.Storage.Backend))
+	case StorageMemory:
+	case StorageBolt, StoragePostgres, StorageSQLite3:
+		if c.Storage.DSN == "" {
+			errs = append(errs, "storage.dsn must be provided for "+c.Storage.Backend+" backend")
+		}
+	}
+
+	if !isHTTPURL(c.Auth.TokenEndpoint) {
+		errs = append(errs, "auth.token_endpoint must be an absolute http or https URL")
+	}
+
+	for name, d := range map[string]Duration{
+		"fetcher.interval":    c.Fetcher.Interval,
+		"fetcher.max_backoff": c.Fetcher.MaxBackoff,
+		"fetcher.tick":        c.Fetcher.Tick,
+		"retention.interval":  c.Retention.Interval,
+		"limits.timeout":      c.Limits.Timeout,
+		"limits.token_ttl":    c.Limits.TokenTTL,
+		"limits.heartbeat":    c.Limits.Heartbeat,
+	} {
+		if d.Duration <= 0 {
+			errs = append(errs, name+" must be positive")
+		}
+	}
+
+	if c.Fetcher.MaxBackoff.Duration < c.Fetcher.Interval.Duration {
+		errs = append(errs, "fetcher.max_backoff must not be less than fetcher.interval")
+	}
+
+	if c.Retention.MaxAge.Duration < 0 {
+		errs = append(errs, "retention.max_age must not be negative")
+	}
+
+	if c.Retention.MaxCount < 0 {
+		errs = append(errs, "retention.max_count must not be negative")
+	}
+
+	if c.Limits.Workers <= 0 {
+		errs = append(errs, "limits.workers must be positive")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	// map iteration is random, keep errors stable for humans
+	sort.Strings(errs)
+
+	return fmt.Errorf("%w: %s", ErrConfig, strings.Join(errs, "; "))
+}
+
+func (c *Config) decodeFile(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read configuration file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	default:
+		return fmt.Errorf("%w: %s", ErrFormat, ext)
+	case ".toml":
+		err = toml.Unmarshal(src, c)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(src, c)
+	}
+
+	if err != nil {
+		return fmt.Errorf("cannot decode configuration file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) applyEnv(env []string) error {
+	vars := map[string]any{
+		"SERVER_ADDR":             &c.Server.Addr,
+		"SERVER_BASE_URL":         &c.Server.BaseURL,
+		"STORAGE_BACKEND":         &c.Storage.Backend,
+		"STORAGE_DSN":             &c.Storage.DSN,
+		"AUTH_TOKEN_ENDPOINT":     &c.Auth.TokenEndpoint,
+		"FETCHER_INTERVAL":        &c.Fetcher.Interval,
+		"FETCHER_MAX_BACKOFF":     &c.Fetcher.MaxBackoff,
+		"FETCHER_TICK":            &c.Fetcher.Tick,
+		"RETENTION_MAX_AGE":       &c.Retention.MaxAge,
+		"RETENTION_MAX_COUNT":     &c.Retention.MaxCount,
+		"RETENTION_KEEP_UNREAD":   &c.Retention.KeepUnread,
+		"RETENTION_NOTIFICATIONS": &c.Retention.Notifications,
+		"RETENTION_INTERVAL":      &c.Retention.Interval,
+		"LIMITS_WORKERS":          &c.Limits.Workers,
+		"LIMITS_TIMEOUT":          &c.Limits.Timeout,
+		"LIMITS_ALLOW_PRIVATE":    &c.Limits.AllowPrivate,
+		"LIMITS_TOKEN_TTL":        &c.Limits.TokenTTL,
+		"LIMITS_HEARTBEAT":        &c.Limits.Heartbeat,
+	}
+
+	for _, kv := range env {
+		key, value, found := strings.Cut(kv, "=")
+		if !found || !strings.HasPrefix(key, EnvPrefix) {
+			continue
+		}
+
+		dst, ok := vars[strings.TrimPrefix(key, EnvPrefix)]
+		if !ok {
+			continue
+		}
+
+		if err := set(dst, value); err != nil {
+			return fmt.Errorf("cannot parse %s environment variable: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// set parses value into configuration field dst, which is a pointer to string,
+// int, bool or Duration.
+func set(dst any, value string) error {
+	switch v := dst.(type) {
+	default:
+		return fmt.Errorf("unsupported field type %T", dst)
+	case *string:
+		*v = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		*v = n
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		*v = b
+	case *Duration:
+		return v.UnmarshalText([]byte(value))
+	}
+
+	return nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	var err error
+	if d.Duration, err = time.ParseDuration(string(text)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+func isHTTPURL(src string) bool {
+	u, err := url.Parse(src)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,89 @@
+package config_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/config"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	expect := config.Default()
+	expect.Server.Addr = "127.0.0.1:8080"
+	expect.Server.BaseURL = "https://sub.example.com/"
+	expect.Auth.TokenEndpoint = "https://tokens.example.com/token"
+	expect.Fetcher.Interval = config.Duration{30 * time.Minute}
+	expect.Fetcher.MaxBackoff = config.Duration{12 * time.Hour}
+	expect.Retention.MaxAge = config.Duration{720 * time.Hour}
+	expect.Retention.MaxCount = 500
+	expect.Retention.KeepUnread = false
+	expect.Limits.Workers = 8
+
+	for _, name := range []string{"config.toml", "config.yaml"} {
+		actual, err := config.Load(filepath.Join("testdata", name), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if diff := cmp.Diff(expect, actual); diff != "" {
+			t.Errorf("%s: %s", name, diff)
+		}
+
+		if err = actual.Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestLoad_Env(t *testing.T) {
+	t.Parallel()
+
+	actual, err := config.Load(filepath.Join("testdata", "config.toml"), []string{
+		"SUB_SERVER_ADDR=:9000",
+		"SUB_FETCHER_TICK=5s",
+		"SUB_LIMITS_WORKERS=2",
+		"SUB_RETENTION_NOTIFICATIONS=true",
+		"SUB_LIMITS_ALLOW_PRIVATE=true",
+		"SUB_UNKNOWN=value",
+		"HOME=/root",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.Server.Addr != ":9000" || actual.Fetcher.Tick.Duration != 5*time.Second ||
+		actual.Limits.Workers != 2 || !actual.Retention.Notifications || !actual.Limits.AllowPrivate {
+		t.Errorf("environment variables are not applied: %+v", actual)
+	}
+
+	if _, err = config.Load("", []string{"SUB_LIMITS_WORKERS=many"}); err == nil {
+		t.Error("want error for invalid number, got nil")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.Storage.Backend = "floppy"
+	cfg.Limits.Workers = 0
+	cfg.Retention.MaxCount = -1
+
+	err := cfg.Validate()
+	if !errors.Is(err, config.ErrConfig) {
+		t.Fatalf("want %v, got %v", config.ErrConfig, err)
+	}
+
+	for _, expect := range []string{"storage.backend", "auth.token_endpoint", "limits.workers", "retention.max_count"} {
+		if !strings.Contains(err.Error(), expect) {
+			t.Errorf("want '%s' in error, got '%s'", expect, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package bolt opens bbolt databases where all data of each user is stored in
+// the own nested bucket.
+package bolt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+// Root buckets.
+var (
+	bucketUsers = []byte("users")
+)
+
+// Open opens database file by path, creating it if needed.
+func Open(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	}
+
+	if err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketUsers)
+
+		return err
+	}); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("cannot create root buckets: %w", err)
+	}
+
+	return db, nil
+}
+
+// Users returns the root bucket with nested bucket of every user by URL.
+func Users(tx *bolt.Tx) (*bolt.Bucket, error) {
+	users := tx.Bucket(bucketUsers)
+	if users == nil {
+		return nil, fmt.Errorf("database is not initialized")
+	}
+
+	return users, nil
+}
+
+// UserBucket returns bucket by name nested into the bucket of user, creating
+// both of them in writable transaction. It returns nil if bucket is not exists
+// in read-only transaction.
+func UserBucket(tx *bolt.Tx, u domain.User, name []byte) (*bolt.Bucket, error) {
+	users, err := Users(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tx.Writable() {
+		user := users.Bucket([]byte(u.String()))
+		if user == nil {
+			return nil, nil
+		}
+
+		return user.Bucket(name), nil
+	}
+
+	user, err := users.CreateBucketIfNotExists([]byte(u.String()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create user bucket: %w", err)
+	}
+
+	out, err := user.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s bucket: %w", name, err)
+	}
+
+	return out, nil
+}
+
+// ForEachUser calls fn with the bucket by name of every user which has it.
+func ForEachUser(tx *bolt.Tx, name []byte, fn func(me string, b *bolt.Bucket) error) error {
+	users, err := Users(tx)
+	if err != nil {
+		return err
+	}
+
+	return users.ForEach(func(me, value []byte) error {
+		// users bucket contains only nested buckets
+		if value != nil {
+			return nil
+		}
+
+		if b := users.Bucket(me).Bucket(name); b != nil {
+			return fn(string(me), b)
+		}
+
+		return nil
+	})
+}
+
+// Int64Key encodes n into 8 bytes which are sorted by bytes in the same order
+// as numbers, including negative ones.
+func Int64Key(n int64) []byte {
+	out := make([]byte, 8)
+	binary.BigEndian.PutUint64(out, uint64(n)^(1<<63))
+
+	return out
+}
+
+// ParseInt64Key decodes number encoded by Int64Key from the prefix of key.
+func ParseInt64Key(key []byte) int64 {
+	return int64(binary.BigEndian.Uint64(key[:8]) ^ (1 << 63))
+}
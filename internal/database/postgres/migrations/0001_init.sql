@@ -0,0 +1,29 @@
+CREATE TABLE channels (
+	me     TEXT    NOT NULL,
+	uid    TEXT    NOT NULL,
+	name   TEXT    NOT NULL,
+	unread TEXT    NOT NULL DEFAULT '',
+	weight INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (me, uid)
+);
+
+CREATE INDEX channels_me_weight ON channels (me, weight);
+
+CREATE TABLE follows (
+	me      TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	url     TEXT NOT NULL,
+	name    TEXT NOT NULL DEFAULT '',
+	photo   TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (me, channel, url)
+);
+
+CREATE TABLE entries (
+	me      TEXT  NOT NULL,
+	id      TEXT  NOT NULL,
+	channel TEXT  NOT NULL,
+	data    JSONB NOT NULL,
+	PRIMARY KEY (me, id)
+);
+
+CREATE INDEX entries_me_channel ON entries (me, channel);
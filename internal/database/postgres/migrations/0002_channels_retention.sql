@@ -0,0 +1,5 @@
+-- channels without policy keep NULL and use the default one
+ALTER TABLE channels
+	ADD COLUMN retention_max_age     BIGINT,
+	ADD COLUMN retention_max_count   INTEGER,
+	ADD COLUMN retention_keep_unread BOOLEAN;
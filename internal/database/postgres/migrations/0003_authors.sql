@@ -0,0 +1,10 @@
+-- muted and blocked users share the table, list tells them apart
+CREATE TABLE authors (
+	me      TEXT NOT NULL,
+	list    TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	url     TEXT NOT NULL,
+	name    TEXT NOT NULL DEFAULT '',
+	photo   TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (me, list, channel, url)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE users (
+	me TEXT NOT NULL PRIMARY KEY
+);
+
+-- users who signed in before the registry are known by their data
+INSERT INTO users (me)
+	SELECT me FROM channels
+	UNION SELECT me FROM follows
+	UNION SELECT me FROM entries
+	UNION SELECT me FROM authors;
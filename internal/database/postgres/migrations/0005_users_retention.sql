@@ -0,0 +1,6 @@
+-- notifications channel is not stored, so its policy is kept by user, users
+-- without policy keep NULL and use the default one
+ALTER TABLE users
+	ADD COLUMN retention_max_age     BIGINT,
+	ADD COLUMN retention_max_count   INTEGER,
+	ADD COLUMN retention_keep_unread BOOLEAN;
@@ -0,0 +1,204 @@
+// Package postgres opens PostgreSQL databases and keeps their schema up to
+// date. Every table is keyed by the user URL in the 'me' column, and every
+// query of repositories filters by it, so data of users never crosses.
+//
+// Row-level security policies are not used: the server connects by a single
+// role which owns the tables, and fetching and compaction read data of all
+// users at once, so policies would be bypassed by the only role anyway.
+// Isolation is a contract of repositories, checked by their conformance
+// tests.
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // registers "postgres" driver
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// TestDSNEnv is an environment variable with DSN of PostgreSQL database used by
+// tests. Tests which need PostgreSQL are skipped if it's not provided.
+const TestDSNEnv string = "SUB_TEST_POSTGRES_DSN"
+
+// migrationsLock is a key of advisory lock which prevents concurrent
+// migrations by several instances.
+const migrationsLock int64 = 0x737562
+
+// Open connects to PostgreSQL database by dsn and applies all pending
+// migrations.
+func Open(ctx context.Context, dsn string) (*sqlx.DB, error) {
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	}
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+
+	if err = Migrate(ctx, db); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Migrate applies embedded migrations which are not recorded in
+// schema_migrations table yet. Migration version is a number prefix of its
+// file name. Each migration is applied in own transaction.
+func Migrate(ctx context.Context, db *sqlx.DB) error {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get connection for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	// advisory locks belong to session, so lock and unlock on the same
+	// connection
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationsLock); err != nil {
+		return fmt.Errorf("cannot lock migrations: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLock)
+
+	if _, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER     PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return fmt.Errorf("cannot create migrations table: %w", err)
+	}
+
+	applied := make([]int, 0)
+	if err = conn.SelectContext(ctx, &applied, "SELECT version FROM schema_migrations"); err != nil {
+		return fmt.Errorf("cannot read applied migrations: %w", err)
+	}
+
+	done := make(map[int]bool, len(applied))
+	for _, version := range applied {
+		done[version] = true
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("cannot list migrations: %w", err)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(path.Base(name), "_")
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return fmt.Errorf("cannot parse version of migration %s: %w", name, err)
+		}
+
+		if done[version] {
+			continue
+		}
+
+		query, err := migrations.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("cannot read migration %s: %w", name, err)
+		}
+
+		tx, err := conn.BeginTxx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin migration %s: %w", name, err)
+		}
+
+		if _, err = tx.ExecContext(ctx, string(query)); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("cannot apply migration %s: %w", name, err)
+		}
+
+		if _, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("cannot record migration %s: %w", name, err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("cannot commit migration %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// TestDatabase returns migrated database in a new schema of PostgreSQL
+// provided by TestDSNEnv, which is dropped after test. Test is skipped if
+// database is not provided or unavailable.
+func TestDatabase(tb testing.TB) *sqlx.DB {
+	tb.Helper()
+
+	dsn := os.Getenv(TestDSNEnv)
+	if dsn == "" {
+		tb.Skipf("%s is not provided", TestDSNEnv)
+	}
+
+	ctx := context.Background()
+
+	admin, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		tb.Skipf("cannot open PostgreSQL database: %s", err)
+	}
+	tb.Cleanup(func() { _ = admin.Close() })
+
+	if err = admin.PingContext(ctx); err != nil {
+		tb.Skipf("PostgreSQL is unavailable: %s", err)
+	}
+
+	id := make([]byte, 8)
+	if _, err = rand.Read(id); err != nil {
+		tb.Fatal(err)
+	}
+
+	schema := "test_" + hex.EncodeToString(id)
+	if _, err = admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() { _, _ = admin.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE") })
+
+	db, err := Open(ctx, withSearchPath(dsn, schema))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+// withSearchPath returns dsn in URL or key=value form which connects to schema
+// by default.
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+
+		return u.String()
+	}
+
+	return dsn + " search_path=" + schema
+}
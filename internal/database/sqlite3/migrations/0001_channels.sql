@@ -0,0 +1,10 @@
+CREATE TABLE channels (
+	me     TEXT    NOT NULL,
+	uid    TEXT    NOT NULL,
+	name   TEXT    NOT NULL,
+	unread TEXT    NOT NULL DEFAULT '',
+	weight INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (me, uid)
+);
+
+CREATE INDEX channels_me_weight ON channels (me, weight);
@@ -0,0 +1,4 @@
+-- channels without policy keep NULL and use the default one
+ALTER TABLE channels ADD COLUMN retention_max_age INTEGER;
+ALTER TABLE channels ADD COLUMN retention_max_count INTEGER;
+ALTER TABLE channels ADD COLUMN retention_keep_unread BOOLEAN;
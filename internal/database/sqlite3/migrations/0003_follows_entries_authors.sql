@@ -0,0 +1,29 @@
+CREATE TABLE follows (
+	me      TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	url     TEXT NOT NULL,
+	name    TEXT NOT NULL DEFAULT '',
+	photo   TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (me, channel, url)
+);
+
+CREATE TABLE entries (
+	me      TEXT NOT NULL,
+	id      TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	data    TEXT NOT NULL,
+	PRIMARY KEY (me, id)
+);
+
+CREATE INDEX entries_me_channel ON entries (me, channel);
+
+-- muted and blocked users share the table, list tells them apart
+CREATE TABLE authors (
+	me      TEXT NOT NULL,
+	list    TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	url     TEXT NOT NULL,
+	name    TEXT NOT NULL DEFAULT '',
+	photo   TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (me, list, channel, url)
+);
@@ -0,0 +1,5 @@
+-- notifications channel is not stored, so its policy is kept by user, users
+-- without policy keep NULL and use the default one
+ALTER TABLE users ADD COLUMN retention_max_age INTEGER;
+ALTER TABLE users ADD COLUMN retention_max_count INTEGER;
+ALTER TABLE users ADD COLUMN retention_keep_unread BOOLEAN;
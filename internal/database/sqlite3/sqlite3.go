@@ -0,0 +1,95 @@
+// Package sqlite3 opens SQLite databases and keeps their schema up to date.
+package sqlite3
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite" // registers pure Go "sqlite" driver
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// pragmas are applied by driver to every opened connection, so they survive
+// reconnects of pool.
+const pragmas string = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+// Open connects to SQLite database by dsn, like 'file:sub.db' or ':memory:',
+// and applies all pending migrations.
+func Open(ctx context.Context, dsn string) (*sqlx.DB, error) {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	db, err := sqlx.Open("sqlite", dsn+separator+pragmas)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	}
+
+	// SQLite allows only one writer, and every connection to ':memory:' is
+	// a separate database
+	db.SetMaxOpenConns(1)
+
+	if err = Migrate(ctx, db); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Migrate applies embedded migrations which are newer than database version
+// stored in user_version pragma. Each migration is applied in own
+// transaction.
+func Migrate(ctx context.Context, db *sqlx.DB) error {
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("cannot list migrations: %w", err)
+	}
+
+	sort.Strings(names)
+
+	var version int
+	if err = db.GetContext(ctx, &version, "PRAGMA user_version"); err != nil {
+		return fmt.Errorf("cannot read database version: %w", err)
+	}
+
+	for i := version; i < len(names); i++ {
+		query, err := migrations.ReadFile(names[i])
+		if err != nil {
+			return fmt.Errorf("cannot read migration %s: %w", names[i], err)
+		}
+
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin migration %s: %w", names[i], err)
+		}
+
+		if _, err = tx.ExecContext(ctx, string(query)); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("cannot apply migration %s: %w", names[i], err)
+		}
+
+		// pragma does not support placeholders
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+
+			return fmt.Errorf("cannot update database version: %w", err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("cannot commit migration %s: %w", names[i], err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package sqlite3_test
+
+import (
+	"context"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/database/sqlite3"
+)
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := sqlite3.Open(ctx, "file:"+t.TempDir()+"/sub.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	// every query gets a new connection, which must be configured as well
+	db.SetMaxIdleConns(0)
+
+	for pragma, expect := range map[string]int{"foreign_keys": 1, "busy_timeout": 5000} {
+		for i := 0; i < 2; i++ {
+			var actual int
+			if err = db.GetContext(ctx, &actual, "PRAGMA "+pragma); err != nil {
+				t.Fatal(err)
+			}
+
+			if actual != expect {
+				t.Errorf("want %s = %d on connection %d, got %d", pragma, expect, i, actual)
+			}
+		}
+	}
+}
@@ -13,12 +13,15 @@ var (
 	ActionUnd      = Action{action: ""}         // "und"
 	ActionBlock    = Action{action: "block"}    // "block"
 	ActionChannels = Action{action: "channels"} // "channels"
+	ActionEvents   = Action{action: "events"}   // "events"
 	ActionFollow   = Action{action: "follow"}   // "follow"
 	ActionMute     = Action{action: "mute"}     // "mute"
 	ActionPreview  = Action{action: "preview"}  // "preview"
 	ActionSearch   = Action{action: "search"}   // "search"
 	ActionTimeline = Action{action: "timeline"} // "timeline"
+	ActionUnblock  = Action{action: "unblock"}  // "unblock"
 	ActionUnfollow = Action{action: "unfollow"} // "unfollow"
+	ActionUnmute   = Action{action: "unmute"}   // "unmute"
 )
 
 var ErrActionSyntax = errors.New("unknown or unsupported action")
@@ -26,12 +29,15 @@ var ErrActionSyntax = errors.New("unknown or unsupported action")
 var stringsActions = map[string]Action{
 	ActionBlock.action:    ActionBlock,
 	ActionChannels.action: ActionChannels,
+	ActionEvents.action:   ActionEvents,
 	ActionFollow.action:   ActionFollow,
 	ActionMute.action:     ActionMute,
 	ActionPreview.action:  ActionPreview,
 	ActionSearch.action:   ActionSearch,
 	ActionTimeline.action: ActionTimeline,
+	ActionUnblock.action:  ActionUnblock,
 	ActionUnfollow.action: ActionUnfollow,
+	ActionUnmute.action:   ActionUnmute,
 }
 
 func ParseAction(src string) (Action, error) {
@@ -10,11 +10,26 @@ import (
 	"source.toby3d.me/toby3d/sub/internal/common"
 )
 
-type Channel struct {
-	UID    string
-	Name   string
-	Weight int
-}
+type (
+	Channel struct {
+		// Unread is a mode of showing unread entries count. Channels
+		// without mode show the count.
+		Unread UnreadMode
+
+		// Retention is an own policy of removing old entries of channel.
+		// Channels without policy use the default one.
+		Retention *Retention
+		UID       string
+		Name      string
+		Weight    int
+	}
+
+	// UserChannel is a channel together with its owner.
+	UserChannel struct {
+		User    User
+		Channel Channel
+	}
+)
 
 func TestChannel(tb testing.TB) *Channel {
 	tb.Helper()
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"golang.org/x/exp/rand"
+)
+
+type (
+	// Entry represents a single jf2 post delivered into channel timeline.
+	Entry struct {
+		Published   time.Time
+		Updated     time.Time
+		Author      *Card
+		Checkin     *Card
+		Content     *Content
+		ID          string
+		Channel     string
+		Source      string
+		UID         string
+		URL         string
+		Name        string
+		Summary     string
+		Photo       []string
+		Video       []string
+		Audio       []string
+		LikeOf      []string
+		RepostOf    []string
+		BookmarkOf  []string
+		InReplyTo   []string
+		Syndication []string
+		Category    []string
+		IsRead      bool
+
+		// Hash is a hash of published content of entry. Removed entry
+		// keeps it, so entry without UID and URL is recognized too.
+		Hash string
+
+		// IsRemoved marks entry removed from channel by user. Such entry
+		// keeps only its identity, so it will not be delivered again.
+		IsRemoved bool
+	}
+
+	// Card represents a h-card of person or place.
+	Card struct {
+		Type          string
+		Name          string
+		URL           string
+		Photo         string
+		Latitude      string
+		Longitude     string
+		StreetAddress string
+		Locality      string
+		Region        string
+		Country       string
+	}
+
+	Content struct {
+		Text string
+		HTML string
+	}
+)
+
+func TestEntry(tb testing.TB) *Entry {
+	tb.Helper()
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		tb.Fatal(err)
+	}
+
+	return &Entry{
+		ID:        hex.EncodeToString(id),
+		UID:       gofakeit.URL(),
+		URL:       gofakeit.URL(),
+		Name:      gofakeit.Sentence(4),
+		Published: gofakeit.Date().UTC().Truncate(time.Second),
+		Author: &Card{
+			Type: "card",
+			Name: gofakeit.Name(),
+			URL:  gofakeit.URL(),
+		},
+		Content: &Content{
+			Text: gofakeit.Sentence(16),
+		},
+	}
+}
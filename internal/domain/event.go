@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// Event describes a change in user data which is streamed to clients in
+	// real time.
+	Event struct {
+		Type EventType
+
+		// Channel is a changed channel or the channel of changed entries.
+		// Deleted channel contains only UID.
+		Channel Channel
+
+		// Entry is a new entry of new-item event.
+		Entry *Entry
+
+		// Entries contains IDs of entries with changed read state.
+		Entries []string
+	}
+
+	EventType struct {
+		eventType string
+	}
+)
+
+var (
+	EventTypeUnd            = EventType{eventType: ""}                // "und"
+	EventTypeChannelChanged = EventType{eventType: "channel-changed"} // "channel-changed"
+	EventTypeChannelCreated = EventType{eventType: "channel-created"} // "channel-created"
+	EventTypeChannelDeleted = EventType{eventType: "channel-deleted"} // "channel-deleted"
+	EventTypeMarkRead       = EventType{eventType: "mark-read"}       // "mark-read"
+	EventTypeMarkUnread     = EventType{eventType: "mark-unread"}     // "mark-unread"
+	EventTypeNewItem        = EventType{eventType: "new-item"}        // "new-item"
+)
+
+var ErrEventTypeSyntax = errors.New("unknown or unsupported event type")
+
+var stringsEventTypes = map[string]EventType{
+	EventTypeChannelChanged.eventType: EventTypeChannelChanged,
+	EventTypeChannelCreated.eventType: EventTypeChannelCreated,
+	EventTypeChannelDeleted.eventType: EventTypeChannelDeleted,
+	EventTypeMarkRead.eventType:       EventTypeMarkRead,
+	EventTypeMarkUnread.eventType:     EventTypeMarkUnread,
+	EventTypeNewItem.eventType:        EventTypeNewItem,
+}
+
+func ParseEventType(src string) (EventType, error) {
+	if eventType, ok := stringsEventTypes[src]; ok {
+		return eventType, nil
+	}
+
+	return EventTypeUnd, fmt.Errorf("%w: %s", ErrEventTypeSyntax, src)
+}
+
+func (t EventType) String() string {
+	if t.eventType != "" {
+		return t.eventType
+	}
+
+	return "und"
+}
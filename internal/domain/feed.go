@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+)
+
+type (
+	// Feed represents a followed source of entries.
+	Feed struct {
+		URL   *url.URL
+		Name  string
+		Photo string
+	}
+
+	// Subscription binds followed feed to the user channel which it
+	// delivers entries into.
+	Subscription struct {
+		User    User
+		Channel string
+		Feed    Feed
+	}
+)
+
+func TestFeed(tb testing.TB) *Feed {
+	tb.Helper()
+
+	u, err := url.Parse(gofakeit.URL())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return &Feed{
+		URL:  u,
+		Name: gofakeit.Company(),
+	}
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Retention is a policy of removing old entries from channel. Zero values of
+// limits disable them, so zero Retention keeps all entries forever.
+type Retention struct {
+	// MaxAge removes entries published earlier than this duration ago.
+	MaxAge time.Duration
+
+	// MaxCount removes entries beyond this count of the newest ones.
+	MaxCount int
+
+	// KeepUnread protects unread entries from both limits.
+	KeepUnread bool
+}
+
+// IsZero reports whether policy does not limit channel.
+func (r Retention) IsZero() bool { return r.MaxAge <= 0 && r.MaxCount <= 0 }
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scope is a permission of IndieAuth token.
+type Scope struct {
+	scope string
+}
+
+var (
+	ScopeUnd      = Scope{scope: ""}         // "und"
+	ScopeBlock    = Scope{scope: "block"}    // "block"
+	ScopeChannels = Scope{scope: "channels"} // "channels"
+	ScopeFollow   = Scope{scope: "follow"}   // "follow"
+	ScopeMute     = Scope{scope: "mute"}     // "mute"
+	ScopeRead     = Scope{scope: "read"}     // "read"
+)
+
+var ErrScopeSyntax = errors.New("unknown or unsupported scope")
+
+var stringsScopes = map[string]Scope{
+	ScopeBlock.scope:    ScopeBlock,
+	ScopeChannels.scope: ScopeChannels,
+	ScopeFollow.scope:   ScopeFollow,
+	ScopeMute.scope:     ScopeMute,
+	ScopeRead.scope:     ScopeRead,
+}
+
+func ParseScope(src string) (Scope, error) {
+	if scope, ok := stringsScopes[src]; ok {
+		return scope, nil
+	}
+
+	return ScopeUnd, fmt.Errorf("%w: %s", ErrScopeSyntax, src)
+}
+
+// ParseScopes parses space separated list of scopes skipping the ones which
+// are not related to Microsub, like 'create' of Micropub.
+func ParseScopes(src string) []Scope {
+	out := make([]Scope, 0)
+
+	for _, s := range strings.Fields(src) {
+		if scope, err := ParseScope(s); err == nil {
+			out = append(out, scope)
+		}
+	}
+
+	return out
+}
+
+func (s Scope) String() string {
+	if s.scope != "" {
+		return s.scope
+	}
+
+	return "und"
+}
@@ -0,0 +1,14 @@
+package domain
+
+// Timeline represents a single page of channel entries.
+type Timeline struct {
+	Paging Paging
+	Items  []Entry
+}
+
+// Paging contains opaque cursors around the timeline page. Before points to
+// newer entries, After points to older ones.
+type Paging struct {
+	After  string
+	Before string
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UnreadMode describes how the count of unread entries of channel is shown to
+// the user.
+type UnreadMode struct {
+	mode string
+}
+
+var (
+	UnreadModeUnd       = UnreadMode{mode: ""}          // "und"
+	UnreadModeCount     = UnreadMode{mode: "count"}     // "count"
+	UnreadModeIndicator = UnreadMode{mode: "indicator"} // "indicator"
+	UnreadModeOff       = UnreadMode{mode: "off"}       // "off"
+)
+
+var ErrUnreadModeSyntax = errors.New("unknown or unsupported unread mode")
+
+var stringsUnreadModes = map[string]UnreadMode{
+	UnreadModeCount.mode:     UnreadModeCount,
+	UnreadModeIndicator.mode: UnreadModeIndicator,
+	UnreadModeOff.mode:       UnreadModeOff,
+}
+
+func ParseUnreadMode(src string) (UnreadMode, error) {
+	if mode, ok := stringsUnreadModes[src]; ok {
+		return mode, nil
+	}
+
+	return UnreadModeUnd, fmt.Errorf("%w: %s", ErrUnreadModeSyntax, src)
+}
+
+func (m UnreadMode) String() string {
+	if m.mode != "" {
+		return m.mode
+	}
+
+	return "und"
+}
@@ -3,10 +3,16 @@ package domain
 import (
 	"net/url"
 	"testing"
+
+	"golang.org/x/exp/slices"
 )
 
 type User struct {
 	*url.URL
+
+	// Scopes contains permissions granted to the client acting on behalf
+	// of the user.
+	Scopes []Scope
 }
 
 func TestUser(tb testing.TB) *User {
@@ -18,5 +24,11 @@ func TestUser(tb testing.TB) *User {
 			Host:   "user.example.com",
 			Path:   "/",
 		},
+		Scopes: []Scope{ScopeRead, ScopeFollow, ScopeMute, ScopeBlock, ScopeChannels},
 	}
 }
+
+// HasScope reports whether the user granted scope to the client.
+func (u User) HasScope(scope Scope) bool {
+	return slices.Contains(u.Scopes, scope)
+}
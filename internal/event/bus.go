@@ -0,0 +1,18 @@
+package event
+
+import (
+	"context"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type Bus interface {
+	// Publish delivers event to all current subscribers of the user. It
+	// never blocks: slow subscribers miss events instead of delaying the
+	// publisher.
+	Publish(ctx context.Context, u domain.User, e domain.Event)
+
+	// Subscribe returns stream of the user events and the function which
+	// must be called to stop receiving them. Stream is closed after that.
+	Subscribe(u domain.User) (<-chan domain.Event, func())
+}
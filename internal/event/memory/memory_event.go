@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/event"
+)
+
+type memoryEventBus struct {
+	mutex       *sync.RWMutex
+	subscribers map[string]map[chan domain.Event]struct{}
+}
+
+// bufferSize is a number of events which subscriber may not read yet before
+// it starts to miss new ones.
+const bufferSize int = 64
+
+func NewMemoryEventBus() event.Bus {
+	return &memoryEventBus{
+		mutex:       new(sync.RWMutex),
+		subscribers: make(map[string]map[chan domain.Event]struct{}),
+	}
+}
+
+func (bus *memoryEventBus) Publish(ctx context.Context, u domain.User, e domain.Event) {
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+
+	for stream := range bus.subscribers[u.String()] {
+		select {
+		case stream <- e:
+		default:
+		}
+	}
+}
+
+func (bus *memoryEventBus) Subscribe(u domain.User) (<-chan domain.Event, func()) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	stream := make(chan domain.Event, bufferSize)
+
+	if _, ok := bus.subscribers[u.String()]; !ok {
+		bus.subscribers[u.String()] = make(map[chan domain.Event]struct{})
+	}
+
+	bus.subscribers[u.String()][stream] = struct{}{}
+
+	once := new(sync.Once)
+
+	return stream, func() {
+		once.Do(func() {
+			bus.mutex.Lock()
+			defer bus.mutex.Unlock()
+
+			delete(bus.subscribers[u.String()], stream)
+
+			if len(bus.subscribers[u.String()]) == 0 {
+				delete(bus.subscribers, u.String())
+			}
+
+			close(stream)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package memory_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/event/memory"
+)
+
+func TestMemoryEventBus_Publish(t *testing.T) {
+	t.Parallel()
+
+	bus := memory.NewMemoryEventBus()
+	user, other := domain.TestUser(t), domain.TestUser(t)
+	other.URL.Host = "other.example.com"
+
+	stream, unsubscribe := bus.Subscribe(*user)
+	defer unsubscribe()
+
+	otherStream, otherUnsubscribe := bus.Subscribe(*other)
+	defer otherUnsubscribe()
+
+	bus.Publish(context.Background(), *user, domain.Event{Type: domain.EventTypeNewItem})
+
+	select {
+	case e := <-stream:
+		if e.Type != domain.EventTypeNewItem {
+			t.Errorf("want %s event, got %s", domain.EventTypeNewItem, e.Type)
+		}
+	default:
+		t.Error("want event, got nothing")
+	}
+
+	select {
+	case e := <-otherStream:
+		t.Errorf("want no events of another user, got %s", e.Type)
+	default:
+	}
+}
+
+func TestMemoryEventBus_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	bus := memory.NewMemoryEventBus()
+	user := domain.TestUser(t)
+	stream, unsubscribe := bus.Subscribe(*user)
+
+	unsubscribe()
+	// second call must not close stream twice
+	unsubscribe()
+
+	if _, ok := <-stream; ok {
+		t.Error("want closed stream after unsubscribe")
+	}
+
+	// publishing without subscribers must not panic on closed stream
+	bus.Publish(context.Background(), *user, domain.Event{Type: domain.EventTypeNewItem})
+}
+
+func TestMemoryEventBus_Publish_Overflow(t *testing.T) {
+	t.Parallel()
+
+	bus := memory.NewMemoryEventBus()
+	user := domain.TestUser(t)
+
+	stream, unsubscribe := bus.Subscribe(*user)
+	defer unsubscribe()
+
+	const size int = 64
+
+	// publisher never blocks on subscriber which does not read events
+	for i := 0; i < 2*size; i++ {
+		bus.Publish(context.Background(), *user, domain.Event{
+			Type:    domain.EventTypeNewItem,
+			Channel: domain.Channel{UID: strconv.Itoa(i)},
+		})
+	}
+
+	if len(stream) != size {
+		t.Fatalf("want %d buffered events, got %d", size, len(stream))
+	}
+
+	// oldest events are kept, newer ones are dropped
+	for i := 0; i < size; i++ {
+		if e := <-stream; e.Channel.UID != strconv.Itoa(i) {
+			t.Errorf("want event #%d, got #%s", i, e.Channel.UID)
+		}
+	}
+}
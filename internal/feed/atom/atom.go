@@ -0,0 +1,206 @@
+// Package atom parses Atom 1.0 syndication documents into jf2 entries.
+package atom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+)
+
+type (
+	// Format parses Atom documents.
+	Format struct{}
+
+	document struct {
+		Title   text     `xml:"http://www.w3.org/2005/Atom title"`
+		Icon    string   `xml:"http://www.w3.org/2005/Atom icon"`
+		Logo    string   `xml:"http://www.w3.org/2005/Atom logo"`
+		Authors []person `xml:"http://www.w3.org/2005/Atom author"`
+		Entries []entry  `xml:"http://www.w3.org/2005/Atom entry"`
+	}
+
+	entry struct {
+		ID         string       `xml:"http://www.w3.org/2005/Atom id"`
+		Title      text         `xml:"http://www.w3.org/2005/Atom title"`
+		Summary    text         `xml:"http://www.w3.org/2005/Atom summary"`
+		Content    text         `xml:"http://www.w3.org/2005/Atom content"`
+		Published  string       `xml:"http://www.w3.org/2005/Atom published"`
+		Updated    string       `xml:"http://www.w3.org/2005/Atom updated"`
+		Authors    []person     `xml:"http://www.w3.org/2005/Atom author"`
+		Links      []link       `xml:"http://www.w3.org/2005/Atom link"`
+		Categories []category   `xml:"http://www.w3.org/2005/Atom category"`
+		Media      []feed.Media `xml:"http://search.yahoo.com/mrss/ content"`
+		Thumbnails []feed.Media `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	}
+
+	// text is an Atom text construct which may contain plain text, escaped
+	// HTML or inline XHTML.
+	text struct {
+		Type  string `xml:"type,attr"`
+		Body  string `xml:",chardata"`
+		Inner string `xml:",innerxml"`
+	}
+
+	person struct {
+		Name string `xml:"http://www.w3.org/2005/Atom name"`
+		URI  string `xml:"http://www.w3.org/2005/Atom uri"`
+	}
+
+	link struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+		Type string `xml:"type,attr"`
+	}
+
+	category struct {
+		Term  string `xml:"term,attr"`
+		Label string `xml:"label,attr"`
+	}
+)
+
+const namespace string = "http://www.w3.org/2005/Atom"
+
+func (Format) Sniff(contentType string, body []byte) bool {
+	switch feed.MediaType(contentType) {
+	case "application/atom+xml":
+		return true
+	case "", "application/xml", "text/xml", "text/plain", "application/octet-stream":
+		root, ok := feed.XMLRoot(body)
+
+		return ok && root.Local == "feed" && root.Space == namespace
+	default:
+		return false
+	}
+}
+
+func (Format) Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	doc := new(document)
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	if err := decoder.Decode(doc); err != nil {
+		return nil, nil, fmt.Errorf("cannot decode Atom document: %w", err)
+	}
+
+	out := &domain.Feed{
+		URL:   base,
+		Name:  doc.Title.plain(),
+		Photo: feed.Resolve(base, doc.Icon),
+	}
+
+	if out.Photo == "" {
+		out.Photo = feed.Resolve(base, doc.Logo)
+	}
+
+	entries := make([]domain.Entry, 0, len(doc.Entries))
+
+	for i := range doc.Entries {
+		e := doc.Entries[i].entry(base)
+
+		// entry inherits feed author if it has no own
+		if e.Author == nil && len(doc.Authors) > 0 {
+			e.Author = doc.Authors[0].card(base)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return out, entries, nil
+}
+
+func (e entry) entry(base *url.URL) domain.Entry {
+	out := domain.Entry{
+		UID:       strings.TrimSpace(e.ID),
+		Name:      e.Title.plain(),
+		Summary:   e.Summary.plain(),
+		Published: feed.ParseTime(e.Published),
+		Updated:   feed.ParseTime(e.Updated),
+	}
+
+	if out.Published.IsZero() {
+		out.Published = out.Updated
+	}
+
+	switch content := e.Content.html(); {
+	case content == "":
+	case e.Content.Type == "", e.Content.Type == "text":
+		out.Content = &domain.Content{Text: content}
+	default:
+		out.Content = &domain.Content{
+			Text: feed.PlainText(content),
+			HTML: content,
+		}
+	}
+
+	if len(e.Authors) > 0 {
+		out.Author = e.Authors[0].card(base)
+	}
+
+	objects := append(append(make([]feed.Media, 0), e.Media...), e.Thumbnails...)
+
+	for _, l := range e.Links {
+		switch l.Rel {
+		case "", "alternate":
+			if out.URL == "" {
+				out.URL = feed.Resolve(base, l.Href)
+			}
+		case "enclosure":
+			objects = append(objects, feed.Media{URL: l.Href, Type: l.Type})
+		}
+	}
+
+	for _, c := range e.Categories {
+		if term := strings.TrimSpace(c.Term); term != "" {
+			out.Category = append(out.Category, term)
+		}
+	}
+
+	feed.AttachMedia(&out, base, objects...)
+
+	if out.UID == "" {
+		out.UID = out.URL
+	}
+
+	return out
+}
+
+// plain returns text construct as plain text.
+func (t text) plain() string {
+	switch t.Type {
+	case "html", "xhtml":
+		return feed.PlainText(t.html())
+	default:
+		return strings.TrimSpace(t.Body)
+	}
+}
+
+// html returns text construct as HTML markup.
+func (t text) html() string {
+	switch t.Type {
+	case "xhtml":
+		return strings.TrimSpace(t.Inner)
+	default:
+		return strings.TrimSpace(t.Body)
+	}
+}
+
+func (p person) card(base *url.URL) *domain.Card {
+	if p.Name == "" && p.URI == "" {
+		return nil
+	}
+
+	return &domain.Card{
+		Type: "card",
+		Name: strings.TrimSpace(p.Name),
+		URL:  feed.Resolve(base, p.URI),
+	}
+}
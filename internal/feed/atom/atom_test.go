@@ -0,0 +1,63 @@
+package atom_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed/atom"
+)
+
+func TestFormat_Parse(t *testing.T) {
+	t.Parallel()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "atom.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, _ := url.Parse("https://blog.example.com/feed.atom")
+
+	feed, entries, err := atom.Format{}.Parse(body, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(&domain.Feed{
+		URL:   base,
+		Name:  "Example Blog",
+		Photo: "https://blog.example.com/icon.png",
+	}, feed); diff != "" {
+		t.Error(diff)
+	}
+
+	expect := []domain.Entry{{
+		UID:       "tag:blog.example.com,2023:1",
+		URL:       "https://blog.example.com/posts/1",
+		Name:      "Hello <World>",
+		Summary:   "Short summary",
+		Published: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+		Updated:   time.Date(2023, time.March, 1, 13, 0, 0, 0, time.UTC),
+		Author:    &domain.Card{Type: "card", Name: "Jane Doe", URL: "https://blog.example.com/"},
+		Content:   &domain.Content{Text: "Hello, World!", HTML: "<p>Hello, <b>World</b>!</p>"},
+		Category:  []string{"indieweb"},
+		Video:     []string{"https://blog.example.com/posts/1.mp4"},
+	}, {
+		UID:       "tag:blog.example.com,2023:2",
+		URL:       "https://blog.example.com/posts/2",
+		Name:      "Plain",
+		Published: time.Date(2023, time.March, 2, 8, 30, 0, 0, time.UTC),
+		Updated:   time.Date(2023, time.March, 2, 8, 30, 0, 0, time.UTC),
+		Author:    &domain.Card{Type: "card", Name: "Bob"},
+		Content:   &domain.Content{Text: "Just text"},
+	}}
+
+	if diff := cmp.Diff(expect, entries); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,178 @@
+// Package feed detects the format of a fetched document and parses it into
+// feed metadata and jf2 entries.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	// Format describes a single supported document format.
+	Format interface {
+		// Sniff reports whether body with provided Content-Type header
+		// value looks like this format.
+		Sniff(contentType string, body []byte) bool
+
+		// Parse decodes body into feed metadata and its entries. Relative
+		// URLs are resolved against base.
+		Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error)
+	}
+
+	// Parser picks the first suitable format for the document.
+	Parser struct {
+		formats []Format
+	}
+)
+
+var ErrUnsupported = errors.New("unsupported feed format")
+
+// layouts contains supported datetime formats in order of preference.
+var layouts = [...]string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// blocks contains HTML elements which separate words in plain text.
+var blocks = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "br": true, "dd": true, "div": true, "dt": true,
+	"figcaption": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true,
+	"li": true, "p": true, "pre": true, "section": true, "td": true, "th": true, "tr": true,
+}
+
+func NewParser(formats ...Format) *Parser {
+	return &Parser{
+		formats: formats,
+	}
+}
+
+func (p *Parser) Parse(contentType string, body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	for _, format := range p.formats {
+		if !format.Sniff(contentType, body) {
+			continue
+		}
+
+		feed, entries, err := format.Parse(body, base)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot parse feed: %w", err)
+		}
+
+		if feed.URL == nil {
+			feed.URL = base
+		}
+
+		return feed, entries, nil
+	}
+
+	return nil, nil, fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+}
+
+// XMLRoot returns the name of the root element of XML document.
+func XMLRoot(body []byte) (xml.Name, bool) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, false
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, true
+		}
+	}
+}
+
+// MediaType returns the media type of Content-Type header value without
+// parameters.
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
+
+// PlainText returns the text content of HTML markup.
+func PlainText(src string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(src))
+	buf := new(strings.Builder)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(buf.String()), " ")
+		case html.TextToken:
+			buf.Write(tokenizer.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			// keep words of adjacent blocks apart
+			if name, _ := tokenizer.TagName(); blocks[string(name)] {
+				buf.WriteByte(' ')
+			}
+		}
+	}
+}
+
+// ParseTime parses datetime in any of formats commonly used by feeds and
+// returns zero time if it's not possible.
+func ParseTime(src string) time.Time {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, src); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Resolve returns ref resolved against base URL. Unparsable references are
+// returned as is.
+func Resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil || base == nil {
+		return ref
+	}
+
+	return base.ResolveReference(u).String()
+}
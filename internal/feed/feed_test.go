@@ -0,0 +1,107 @@
+package feed_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/feed/atom"
+	"source.toby3d.me/toby3d/sub/internal/feed/jsonfeed"
+	"source.toby3d.me/toby3d/sub/internal/feed/mf2"
+	"source.toby3d.me/toby3d/sub/internal/feed/rss"
+)
+
+func TestParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	parser := feed.NewParser(jsonfeed.Format{}, atom.Format{}, rss.Format{}, mf2.Format{})
+	base, _ := url.Parse("https://example.com/")
+
+	for name, tc := range map[string]struct {
+		contentType string
+		body        string
+		expect      string
+	}{
+		"rss": {
+			contentType: "application/rss+xml",
+			body:        `<rss version="2.0"><channel><title>RSS</title></channel></rss>`,
+			expect:      "RSS",
+		},
+		"rss/sniffing": {
+			contentType: "text/xml; charset=utf-8",
+			body:        `<?xml version="1.0"?><rss version="2.0"><channel><title>RSS</title></channel></rss>`,
+			expect:      "RSS",
+		},
+		"atom": {
+			contentType: "application/atom+xml",
+			body:        `<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title></feed>`,
+			expect:      "Atom",
+		},
+		"atom/sniffing": {
+			body:   `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title></feed>`,
+			expect: "Atom",
+		},
+		"jsonfeed": {
+			contentType: "application/feed+json",
+			body:        `{"version":"https://jsonfeed.org/version/1.1","title":"JSON","items":[]}`,
+			expect:      "JSON",
+		},
+		"jsonfeed/sniffing": {
+			contentType: "application/json",
+			body:        `{"version":"https://jsonfeed.org/version/1","title":"JSON","items":[]}`,
+			expect:      "JSON",
+		},
+		"mf2": {
+			contentType: "text/html; charset=utf-8",
+			body:        `<div class="h-feed"><h1 class="p-name">HTML</h1></div>`,
+			expect:      "HTML",
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, _, err := parser.Parse(tc.contentType, []byte(tc.body), base)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Name != tc.expect {
+				t.Errorf("expect '%s', got '%s'", tc.expect, result.Name)
+			}
+		})
+	}
+
+	if _, _, err := parser.Parse("application/json", []byte(`{"hello":"world"}`), base); !errors.Is(err,
+		feed.ErrUnsupported) {
+		t.Errorf("expect %v, got %v", feed.ErrUnsupported, err)
+	}
+}
+
+func TestAttachMedia(t *testing.T) {
+	t.Parallel()
+
+	base, _ := url.Parse("https://example.com/")
+	actual := new(domain.Entry)
+
+	feed.AttachMedia(actual, base,
+		feed.Media{URL: "/a.jpg"},
+		feed.Media{URL: "/a.jpg", Type: "image/jpeg"},
+		feed.Media{URL: "/b.mp4", Type: "video/mp4"},
+		feed.Media{URL: "/c", Medium: "audio", Type: "application/octet-stream"},
+		feed.Media{URL: "/d.pdf", Type: "application/pdf"},
+		feed.Media{})
+
+	if diff := cmp.Diff(&domain.Entry{
+		Photo: []string{"https://example.com/a.jpg"},
+		Video: []string{"https://example.com/b.mp4"},
+		Audio: []string{"https://example.com/c"},
+	}, actual); diff != "" {
+		t.Error(diff)
+	}
+}
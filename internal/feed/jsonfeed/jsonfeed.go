@@ -0,0 +1,215 @@
+// Package jsonfeed parses JSON Feed 1.0 and 1.1 documents into jf2 entries.
+package jsonfeed
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"golang.org/x/exp/slices"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+)
+
+type (
+	// Format parses JSON Feed documents.
+	Format struct{}
+
+	document struct {
+		Author      *author  `json:"author"` // deprecated in 1.1
+		Version     string   `json:"version"`
+		Title       string   `json:"title"`
+		HomePageURL string   `json:"home_page_url"`
+		Icon        string   `json:"icon"`
+		Favicon     string   `json:"favicon"`
+		Authors     []author `json:"authors"`
+		Items       []item   `json:"items"`
+	}
+
+	item struct {
+		Author        *author         `json:"author"` // deprecated in 1.1
+		ID            json.RawMessage `json:"id"`     // some feeds use numbers
+		URL           string          `json:"url"`
+		ExternalURL   string          `json:"external_url"`
+		Title         string          `json:"title"`
+		ContentHTML   string          `json:"content_html"`
+		ContentText   string          `json:"content_text"`
+		Summary       string          `json:"summary"`
+		Image         string          `json:"image"`
+		BannerImage   string          `json:"banner_image"`
+		DatePublished string          `json:"date_published"`
+		DateModified  string          `json:"date_modified"`
+		Authors       []author        `json:"authors"`
+		Tags          []string        `json:"tags"`
+		Attachments   []attachment    `json:"attachments"`
+	}
+
+	author struct {
+		Name   string `json:"name"`
+		URL    string `json:"url"`
+		Avatar string `json:"avatar"`
+	}
+
+	attachment struct {
+		URL      string `json:"url"`
+		MIMEType string `json:"mime_type"`
+	}
+)
+
+const version string = "https://jsonfeed.org/version/"
+
+func (Format) Sniff(contentType string, body []byte) bool {
+	switch feed.MediaType(contentType) {
+	case "application/feed+json":
+		return true
+	case "", "application/json", "text/json", "text/plain", "application/octet-stream":
+		if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			return false
+		}
+
+		doc := new(struct {
+			Version string `json:"version"`
+		})
+
+		return json.Unmarshal(body, doc) == nil && strings.HasPrefix(doc.Version, version)
+	default:
+		return false
+	}
+}
+
+func (Format) Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	doc := new(document)
+	if err := json.Unmarshal(body, doc); err != nil {
+		return nil, nil, fmt.Errorf("cannot decode JSON Feed document: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.Version, version) {
+		return nil, nil, fmt.Errorf("unsupported JSON Feed version: '%s'", doc.Version)
+	}
+
+	out := &domain.Feed{
+		URL:   base,
+		Name:  strings.TrimSpace(doc.Title),
+		Photo: feed.Resolve(base, doc.Icon),
+	}
+
+	if out.Photo == "" {
+		out.Photo = feed.Resolve(base, doc.Favicon)
+	}
+
+	authors := doc.Authors
+	if len(authors) == 0 && doc.Author != nil {
+		authors = []author{*doc.Author}
+	}
+
+	entries := make([]domain.Entry, 0, len(doc.Items))
+
+	for i := range doc.Items {
+		e := doc.Items[i].entry(base)
+
+		// entry inherits feed author if it has no own
+		if e.Author == nil && len(authors) > 0 {
+			e.Author = authors[0].card(base)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return out, entries, nil
+}
+
+func (i item) entry(base *url.URL) domain.Entry {
+	out := domain.Entry{
+		URL:       feed.Resolve(base, i.URL),
+		Name:      strings.TrimSpace(i.Title),
+		Summary:   strings.TrimSpace(i.Summary),
+		Published: feed.ParseTime(i.DatePublished),
+		Updated:   feed.ParseTime(i.DateModified),
+		Category:  i.Tags,
+	}
+
+	if err := json.Unmarshal(i.ID, &out.UID); err != nil {
+		out.UID = string(i.ID)
+	}
+
+	if out.UID = strings.TrimSpace(out.UID); out.UID == "null" {
+		out.UID = ""
+	}
+
+	if out.UID == "" {
+		out.UID = out.URL
+	}
+
+	if out.Published.IsZero() {
+		out.Published = out.Updated
+	}
+
+	switch {
+	case i.ContentHTML != "":
+		out.Content = &domain.Content{
+			Text: feed.PlainText(i.ContentHTML),
+			HTML: i.ContentHTML,
+		}
+
+		if i.ContentText != "" {
+			out.Content.Text = strings.TrimSpace(i.ContentText)
+		}
+	case i.ContentText != "":
+		out.Content = &domain.Content{Text: strings.TrimSpace(i.ContentText)}
+	}
+
+	// external page is not a bookmark, it's just a link of item without
+	// own permalink
+	if out.URL == "" {
+		out.URL = feed.Resolve(base, i.ExternalURL)
+	}
+
+	switch {
+	case len(i.Authors) > 0:
+		out.Author = i.Authors[0].card(base)
+	case i.Author != nil:
+		out.Author = i.Author.card(base)
+	}
+
+	for _, src := range []string{i.Image, i.BannerImage} {
+		if u := feed.Resolve(base, src); u != "" && !slices.Contains(out.Photo, u) {
+			out.Photo = append(out.Photo, u)
+		}
+	}
+
+	for _, a := range i.Attachments {
+		u := feed.Resolve(base, a.URL)
+		if u == "" {
+			continue
+		}
+
+		switch kind, _, _ := strings.Cut(a.MIMEType, "/"); kind {
+		case "image":
+			if !slices.Contains(out.Photo, u) {
+				out.Photo = append(out.Photo, u)
+			}
+		case "video":
+			out.Video = append(out.Video, u)
+		case "audio":
+			out.Audio = append(out.Audio, u)
+		}
+	}
+
+	return out
+}
+
+func (a author) card(base *url.URL) *domain.Card {
+	if a.Name == "" && a.URL == "" {
+		return nil
+	}
+
+	return &domain.Card{
+		Type:  "card",
+		Name:  strings.TrimSpace(a.Name),
+		URL:   feed.Resolve(base, a.URL),
+		Photo: feed.Resolve(base, a.Avatar),
+	}
+}
@@ -0,0 +1,76 @@
+package jsonfeed_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed/jsonfeed"
+)
+
+func TestFormat_Parse(t *testing.T) {
+	t.Parallel()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "feed.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, _ := url.Parse("https://links.example.com/feed.json")
+
+	feed, entries, err := jsonfeed.Format{}.Parse(body, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(&domain.Feed{
+		URL:   base,
+		Name:  "Example Links",
+		Photo: "https://links.example.com/icon.png",
+	}, feed); diff != "" {
+		t.Error(diff)
+	}
+
+	expect := []domain.Entry{{
+		UID:       "1",
+		URL:       "https://links.example.com/1",
+		Name:      "IndieWeb",
+		Published: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+		Content:   &domain.Content{Text: "Nice wiki", HTML: "<p>Nice <em>wiki</em></p>"},
+		Category:  []string{"indieweb", "wiki"},
+		Audio:     []string{"https://links.example.com/1.ogg"},
+		Author: &domain.Card{
+			Type:  "card",
+			Name:  "Jane Doe",
+			URL:   "https://links.example.com/",
+			Photo: "https://links.example.com/jane.jpg",
+		},
+	}, {
+		UID:       "2",
+		URL:       "https://links.example.com/2",
+		Published: time.Date(2023, time.March, 2, 8, 30, 0, 0, time.FixedZone("", 0)),
+		Content:   &domain.Content{Text: "Just text"},
+		Photo:     []string{"https://links.example.com/2.png"},
+		Author:    &domain.Card{Type: "card", Name: "Bob"},
+	}, {
+		UID:       "3",
+		URL:       "https://example.org/",
+		Published: time.Date(2023, time.March, 3, 10, 0, 0, 0, time.UTC),
+		Content:   &domain.Content{Text: "Link only"},
+		Author: &domain.Card{
+			Type:  "card",
+			Name:  "Jane Doe",
+			URL:   "https://links.example.com/",
+			Photo: "https://links.example.com/jane.jpg",
+		},
+	}}
+
+	if diff := cmp.Diff(expect, entries); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,61 @@
+package feed
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+// Media is a Media RSS object or an enclosure of RSS and Atom entries.
+type Media struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Medium string `xml:"medium,attr"`
+}
+
+// Kind returns the medium of media object, guessing it by MIME type if it's
+// not provided explicitly. Thumbnails have neither, so they are images.
+func (m Media) Kind() string {
+	if m.Medium != "" {
+		return m.Medium
+	}
+
+	if m.Type == "" {
+		return "image"
+	}
+
+	kind, _, _ := strings.Cut(m.Type, "/")
+
+	return kind
+}
+
+// AttachMedia adds URLs of objects resolved against base to photos, videos
+// and audios of entry by their kind, skipping duplicates.
+func AttachMedia(e *domain.Entry, base *url.URL, objects ...Media) {
+	for _, m := range objects {
+		u := Resolve(base, m.URL)
+		if u == "" {
+			continue
+		}
+
+		var dst *[]string
+
+		switch m.Kind() {
+		default:
+			continue
+		case "image":
+			dst = &e.Photo
+		case "video":
+			dst = &e.Video
+		case "audio":
+			dst = &e.Audio
+		}
+
+		if !slices.Contains(*dst, u) {
+			*dst = append(*dst, u)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package mf2 parses h-feed and h-entry microformats2 markup of HTML pages into
+// jf2 entries.
+package mf2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+)
+
+// Format parses HTML documents with microformats2 markup.
+type Format struct{}
+
+var ErrNoEntries = errors.New("document does not contain h-feed or h-entry")
+
+func (Format) Sniff(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	}
+
+	head := bytes.ToLower(bytes.TrimSpace(body))
+
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
+
+func (Format) Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	return Parse(body, base)
+}
+
+// Parse finds the first h-feed of the page, or top-level h-entry items if
+// there is none, and converts them into feed and its entries.
+func Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	doc, err := parse(body, base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse HTML document: %w", err)
+	}
+
+	out := &domain.Feed{URL: base}
+	items := doc.items
+
+	if hFeed := doc.find("h-feed"); hFeed != nil {
+		out.Name = hFeed.first("name")
+		out.Photo = hFeed.first("photo")
+
+		if out.Photo == "" {
+			if author := doc.author(hFeed, nil); author != nil {
+				out.Photo = author.Photo
+			}
+		}
+
+		items = hFeed.children
+		for _, v := range hFeed.properties["entry"] {
+			if v.item != nil {
+				items = append(items, v.item)
+			}
+		}
+	}
+
+	entries := make([]domain.Entry, 0, len(items))
+
+	for _, i := range items {
+		if !i.is("h-entry") && !i.is("h-event") && !i.is("h-cite") {
+			continue
+		}
+
+		entries = append(entries, doc.entry(i, doc.find("h-feed")))
+	}
+
+	if len(entries) == 0 && doc.find("h-feed") == nil {
+		return nil, nil, ErrNoEntries
+	}
+
+	return out, entries, nil
+}
+
+// find returns the first item of provided type in document, searching top-level
+// items and their children.
+func (doc *document) find(t string) *item {
+	queue := append(make([]*item, 0, len(doc.items)), doc.items...)
+
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+
+		if i.is(t) {
+			return i
+		}
+
+		queue = append(queue, i.children...)
+	}
+
+	return nil
+}
+
+func (doc *document) entry(i, parent *item) domain.Entry {
+	out := domain.Entry{
+		UID:         i.first("uid"),
+		URL:         i.first("url"),
+		Name:        i.first("name"),
+		Summary:     i.first("summary"),
+		Published:   feed.ParseTime(i.first("published")),
+		Updated:     feed.ParseTime(i.first("updated")),
+		Author:      doc.author(i, parent),
+		Photo:       i.urls("photo"),
+		Video:       i.urls("video"),
+		Audio:       i.urls("audio"),
+		LikeOf:      i.urls("like-of"),
+		RepostOf:    i.urls("repost-of"),
+		BookmarkOf:  i.urls("bookmark-of"),
+		InReplyTo:   i.urls("in-reply-to"),
+		Syndication: i.urls("syndication"),
+		Category:    i.urls("category"),
+	}
+
+	if values := i.properties["content"]; len(values) > 0 {
+		out.Content = &domain.Content{
+			Text: values[0].text,
+			HTML: values[0].html,
+		}
+
+		// name which just duplicates the content is not a title
+		if out.Name == collapse(values[0].text) {
+			out.Name = ""
+		}
+	}
+
+	for _, key := range []string{"checkin", "location"} {
+		for _, v := range i.properties[key] {
+			if v.item != nil {
+				out.Checkin = newCard(v.item)
+
+				break
+			}
+		}
+
+		if out.Checkin != nil {
+			break
+		}
+	}
+
+	if out.UID == "" {
+		out.UID = out.URL
+	}
+
+	return out
+}
+
+// author implements the authorship algorithm: explicit author property of the
+// entry, then author of the parent feed, then rel=author of the page, then
+// the representative h-card of the page.
+func (doc *document) author(i, parent *item) *domain.Card {
+	for _, src := range []*item{i, parent} {
+		if src == nil {
+			continue
+		}
+
+		for _, v := range src.properties["author"] {
+			if v.item != nil && v.item.is("h-card") {
+				return newCard(v.item)
+			}
+
+			if v.text == "" {
+				continue
+			}
+
+			// author may be a plain name or URL of the author's page
+			if u, err := url.Parse(v.text); err == nil && u.IsAbs() {
+				if card := doc.card(v.text); card != nil {
+					return card
+				}
+
+				return &domain.Card{Type: "card", URL: v.text}
+			}
+
+			return &domain.Card{Type: "card", Name: v.text}
+		}
+	}
+
+	for _, rel := range doc.rels["author"] {
+		if card := doc.card(rel); card != nil {
+			return card
+		}
+
+		return &domain.Card{Type: "card", URL: rel}
+	}
+
+	var representative *item
+
+	for _, i := range doc.items {
+		if !i.is("h-card") {
+			continue
+		}
+
+		if representative != nil {
+			return nil
+		}
+
+		representative = i
+	}
+
+	if representative == nil {
+		return nil
+	}
+
+	return newCard(representative)
+}
+
+// card returns top-level h-card with provided URL.
+func (doc *document) card(u string) *domain.Card {
+	for _, i := range doc.items {
+		if !i.is("h-card") {
+			continue
+		}
+
+		for _, v := range i.urls("url") {
+			if strings.TrimSuffix(v, "/") == strings.TrimSuffix(u, "/") {
+				return newCard(i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// urls returns string values of property, using the url of nested
+// microformats such as h-cite or h-card.
+func (i *item) urls(name string) []string {
+	values := i.properties[name]
+	if len(values) == 0 {
+		return nil
+	}
+
+	out := make([]string, 0, len(values))
+
+	for _, v := range values {
+		s := v.text
+		if v.item != nil {
+			if u := v.item.first("url"); u != "" {
+				s = u
+			}
+		}
+
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+func newCard(i *item) *domain.Card {
+	out := &domain.Card{
+		Type:          "card",
+		Name:          i.first("name"),
+		URL:           i.first("url"),
+		Photo:         i.first("photo"),
+		Latitude:      i.first("latitude"),
+		Longitude:     i.first("longitude"),
+		StreetAddress: i.first("street-address"),
+		Locality:      i.first("locality"),
+		Region:        i.first("region"),
+		Country:       i.first("country-name"),
+	}
+
+	// location may be provided as nested h-adr or h-geo
+	for _, key := range []string{"adr", "geo"} {
+		for _, v := range i.properties[key] {
+			if v.item == nil {
+				continue
+			}
+
+			nested := newCard(v.item)
+			if out.Latitude == "" {
+				out.Latitude, out.Longitude = nested.Latitude, nested.Longitude
+			}
+
+			if out.Locality == "" {
+				out.StreetAddress, out.Locality = nested.StreetAddress, nested.Locality
+				out.Region, out.Country = nested.Region, nested.Country
+			}
+		}
+	}
+
+	return out
+}
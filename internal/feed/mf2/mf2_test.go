@@ -0,0 +1,105 @@
+package mf2_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed/mf2"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "h-feed.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, _ := url.Parse("https://jane.example.com/notes")
+
+	feed, entries, err := mf2.Parse(body, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(&domain.Feed{
+		URL:   base,
+		Name:  "Jane's notes",
+		Photo: "https://jane.example.com/photo.jpg",
+	}, feed); diff != "" {
+		t.Error(diff)
+	}
+
+	jane := &domain.Card{
+		Type:  "card",
+		Name:  "Jane Doe",
+		URL:   "https://jane.example.com/",
+		Photo: "https://jane.example.com/photo.jpg",
+	}
+
+	expect := []domain.Entry{{
+		UID:       "https://jane.example.com/notes/1",
+		URL:       "https://jane.example.com/notes/1",
+		Published: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+		Author:    jane,
+		Content:   &domain.Content{Text: "Hello, World!", HTML: "Hello, <b>World</b>!"},
+		Category:  []string{"indieweb"},
+		Photo:     []string{"https://jane.example.com/media/1.jpg"},
+	}, {
+		UID:       "https://jane.example.com/likes/2",
+		URL:       "https://jane.example.com/likes/2",
+		Published: time.Date(2023, time.March, 2, 8, 30, 0, 0, time.FixedZone("", 0)),
+		Author:    &domain.Card{Type: "card", Name: "Bob", URL: "https://bob.example.com/"},
+		LikeOf:    []string{"https://alice.example.com/posts/3"},
+	}, {
+		UID:       "https://jane.example.com/checkins/4",
+		URL:       "https://jane.example.com/checkins/4",
+		Name:      "Coffee",
+		Published: time.Date(2023, time.March, 3, 0, 0, 0, 0, time.UTC),
+		Author:    jane,
+		Checkin: &domain.Card{
+			Type:      "card",
+			Name:      "Cafe",
+			Latitude:  "45.5",
+			Longitude: "-122.6",
+			Locality:  "Portland",
+		},
+		Video: []string{"https://jane.example.com/media/4.mp4"},
+		Audio: []string{"https://jane.example.com/media/4.mp3"},
+	}}
+
+	if diff := cmp.Diff(expect, entries); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestFormat_Sniff(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		contentType string
+		body        string
+		expect      bool
+	}{
+		"html":     {contentType: "text/html; charset=utf-8", expect: true},
+		"xhtml":    {contentType: "application/xhtml+xml", expect: true},
+		"sniffing": {body: "  <!DOCTYPE html><html></html>", expect: true},
+		"json":     {contentType: "application/json", body: "<html>", expect: false},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := (mf2.Format{}).Sniff(tc.contentType, []byte(tc.body)); actual != tc.expect {
+				t.Errorf("expect %t, got %t", tc.expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,504 @@
+package mf2
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type (
+	// item is a single parsed microformats2 object.
+	item struct {
+		properties map[string][]value
+		prefixes   map[string]struct{}
+		types      []string
+		children   []*item
+	}
+
+	// value is a single property value: a plain string, HTML markup of
+	// embedded content or nested microformat.
+	value struct {
+		item *item
+		text string
+		html string
+	}
+
+	// document contains all top-level microformats and rel values of the
+	// page.
+	document struct {
+		rels  map[string][]string
+		items []*item
+	}
+
+	parser struct {
+		base *url.URL
+		rels map[string][]string
+	}
+)
+
+// parse extracts microformats2 objects from HTML document.
+func parse(body []byte, base *url.URL) (*document, error) {
+	root, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		base: base,
+		rels: make(map[string][]string),
+	}
+
+	// respect the <base> element if it's provided
+	if n := find(root, func(n *html.Node) bool { return n.DataAtom == atom.Base && attr(n, "href") != "" }); n != nil {
+		p.base = p.resolve(attr(n, "href"))
+	}
+
+	out := new(document)
+	out.items = p.walk(root)
+	out.rels = p.rels
+
+	return out, nil
+}
+
+// walk collects all root microformats inside children of n.
+func (p *parser) walk(n *html.Node) []*item {
+	out := make([]*item, 0)
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+
+		p.collectRels(c)
+
+		if types, _ := classes(c); len(types) > 0 {
+			out = append(out, p.parseItem(c, types))
+
+			continue
+		}
+
+		out = append(out, p.walk(c)...)
+	}
+
+	return out
+}
+
+func (p *parser) collectRels(n *html.Node) {
+	if n.DataAtom != atom.A && n.DataAtom != atom.Link && n.DataAtom != atom.Area {
+		return
+	}
+
+	href := attr(n, "href")
+	if href == "" {
+		return
+	}
+
+	for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+		p.rels[rel] = append(p.rels[rel], p.resolve(href).String())
+	}
+}
+
+func (p *parser) parseItem(n *html.Node, types []string) *item {
+	out := &item{
+		types:      types,
+		properties: make(map[string][]value),
+		prefixes:   make(map[string]struct{}),
+	}
+
+	p.parseProperties(n, out)
+	p.implyProperties(n, out)
+
+	return out
+}
+
+// parseProperties collects properties and child microformats from descendants
+// of n into target.
+func (p *parser) parseProperties(n *html.Node, target *item) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+
+		p.collectRels(c)
+
+		types, props := classes(c)
+
+		var nested *item
+		if len(types) > 0 {
+			nested = p.parseItem(c, types)
+		}
+
+		for _, prop := range props {
+			prefix, name, _ := strings.Cut(prop, "-")
+
+			var v value
+
+			switch prefix {
+			case "p":
+				v.text = p.textValue(c)
+			case "u":
+				v.text = p.urlValue(c)
+			case "dt":
+				v.text = p.dateValue(c)
+			case "e":
+				v.text = strings.TrimSpace(text(c))
+				v.html = strings.TrimSpace(innerHTML(c))
+			}
+
+			if nested != nil {
+				v.item = nested
+
+				// nested microformat takes its string value from the
+				// name or url property
+				switch prefix {
+				case "p":
+					if s := nested.first("name"); s != "" {
+						v.text = s
+					}
+				case "u":
+					if s := nested.first("url"); s != "" {
+						v.text = s
+					}
+				}
+			}
+
+			target.properties[name] = append(target.properties[name], v)
+			target.prefixes[prefix] = struct{}{}
+		}
+
+		switch {
+		case nested != nil && len(props) == 0:
+			target.children = append(target.children, nested)
+		case nested == nil:
+			p.parseProperties(c, target)
+		}
+	}
+}
+
+func (p *parser) implyProperties(n *html.Node, target *item) {
+	hasProperty := func(prefixes ...string) bool {
+		for _, prefix := range prefixes {
+			if _, ok := target.prefixes[prefix]; ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	nestedItems := len(target.children) > 0
+	for _, values := range target.properties {
+		for _, v := range values {
+			nestedItems = nestedItems || v.item != nil
+		}
+	}
+
+	if _, ok := target.properties["name"]; !ok && !nestedItems && !hasProperty("p", "e") {
+		name := attr(n, "alt")
+		if n.DataAtom == atom.Abbr {
+			name = attr(n, "title")
+		}
+
+		if name == "" {
+			name = strings.TrimSpace(text(n))
+		}
+
+		target.properties["name"] = []value{{text: collapse(name)}}
+	}
+
+	if _, ok := target.properties["photo"]; !ok && !hasProperty("u") {
+		if img := only(n, atom.Img); img != nil && attr(img, "src") != "" {
+			target.properties["photo"] = []value{{text: p.resolve(attr(img, "src")).String()}}
+		}
+	}
+
+	if _, ok := target.properties["url"]; !ok && !hasProperty("u") {
+		if a := only(n, atom.A); a != nil && attr(a, "href") != "" {
+			target.properties["url"] = []value{{text: p.resolve(attr(a, "href")).String()}}
+		}
+	}
+}
+
+func (p *parser) textValue(n *html.Node) string {
+	if v, ok := valueClass(n); ok {
+		return v
+	}
+
+	switch n.DataAtom {
+	case atom.Abbr, atom.Link:
+		if v := attr(n, "title"); v != "" {
+			return v
+		}
+	case atom.Data, atom.Input:
+		if v := attr(n, "value"); v != "" {
+			return v
+		}
+	case atom.Img, atom.Area:
+		if v := attr(n, "alt"); v != "" {
+			return v
+		}
+	}
+
+	return collapse(text(n))
+}
+
+func (p *parser) urlValue(n *html.Node) string {
+	var v string
+
+	switch n.DataAtom {
+	case atom.A, atom.Area, atom.Link:
+		v = attr(n, "href")
+	case atom.Img, atom.Audio, atom.Video, atom.Source, atom.Iframe:
+		v = attr(n, "src")
+		if v == "" && n.DataAtom == atom.Video {
+			v = attr(n, "poster")
+		}
+	case atom.Object:
+		v = attr(n, "data")
+	}
+
+	if v == "" {
+		v = p.textValue(n)
+	}
+
+	if v == "" {
+		return ""
+	}
+
+	return p.resolve(v).String()
+}
+
+func (p *parser) dateValue(n *html.Node) string {
+	if v, ok := valueClass(n); ok {
+		return v
+	}
+
+	switch n.DataAtom {
+	case atom.Time, atom.Ins, atom.Del:
+		if v := attr(n, "datetime"); v != "" {
+			return v
+		}
+	case atom.Abbr:
+		if v := attr(n, "title"); v != "" {
+			return v
+		}
+	case atom.Data, atom.Input:
+		if v := attr(n, "value"); v != "" {
+			return v
+		}
+	}
+
+	return strings.TrimSpace(text(n))
+}
+
+func (p *parser) resolve(ref string) *url.URL {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return &url.URL{Path: ref}
+	}
+
+	if p.base == nil {
+		return u
+	}
+
+	return p.base.ResolveReference(u)
+}
+
+// first returns the string value of the first property by name.
+func (i *item) first(name string) string {
+	if values := i.properties[name]; len(values) > 0 {
+		return values[0].text
+	}
+
+	return ""
+}
+
+func (i *item) is(t string) bool {
+	for _, v := range i.types {
+		if v == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classes splits class names of n into microformat root types and property
+// names.
+func classes(n *html.Node) (types, props []string) {
+	for _, class := range strings.Fields(attr(n, "class")) {
+		prefix, name, found := strings.Cut(class, "-")
+		if !found || name == "" || strings.ToLower(name) != name {
+			continue
+		}
+
+		switch prefix {
+		case "h":
+			types = append(types, class)
+		case "p", "u", "dt", "e":
+			props = append(props, class)
+		}
+	}
+
+	return types, props
+}
+
+// valueClass implements the value class pattern: values of all descendants
+// with the 'value' class are concatenated.
+func valueClass(n *html.Node) (string, bool) {
+	values := make([]string, 0)
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+
+			if types, _ := classes(c); len(types) > 0 {
+				continue
+			}
+
+			if !hasClass(c, "value") && !hasClass(c, "value-title") {
+				walk(c)
+
+				continue
+			}
+
+			switch {
+			case hasClass(c, "value-title"):
+				values = append(values, attr(c, "title"))
+			case c.DataAtom == atom.Img || c.DataAtom == atom.Area:
+				values = append(values, attr(c, "alt"))
+			case c.DataAtom == atom.Data || c.DataAtom == atom.Input:
+				values = append(values, attr(c, "value"))
+			case c.DataAtom == atom.Abbr:
+				values = append(values, attr(c, "title"))
+			case c.DataAtom == atom.Time || c.DataAtom == atom.Ins || c.DataAtom == atom.Del:
+				if v := attr(c, "datetime"); v != "" {
+					values = append(values, v)
+
+					continue
+				}
+
+				values = append(values, text(c))
+			default:
+				values = append(values, text(c))
+			}
+		}
+	}
+	walk(n)
+
+	if len(values) == 0 {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.Join(values, "")), true
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, v := range strings.Fields(attr(n, "class")) {
+		if v == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// only returns the single child element of n (or n itself) of provided type,
+// following the implied properties rules.
+func only(n *html.Node, a atom.Atom) *html.Node {
+	for depth := 0; depth < 2 && n != nil; depth++ {
+		if n.DataAtom == a {
+			if types, _ := classes(n); depth == 0 || len(types) == 0 {
+				return n
+			}
+		}
+
+		var child *html.Node
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+
+			if child != nil {
+				return nil
+			}
+
+			child = c
+		}
+
+		n = child
+	}
+
+	return nil
+}
+
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && match(c) {
+			return c
+		}
+
+		if out := find(c, match); out != nil {
+			return out
+		}
+	}
+
+	return nil
+}
+
+// text returns text content of n, replacing images with their alt text and
+// dropping scripts and styles.
+func text(n *html.Node) string {
+	buf := new(strings.Builder)
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			buf.WriteString(n.Data)
+		case n.DataAtom == atom.Script, n.DataAtom == atom.Style:
+			return
+		case n.DataAtom == atom.Img:
+			buf.WriteString(attr(n, "alt"))
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return buf.String()
+}
+
+func innerHTML(n *html.Node) string {
+	buf := new(bytes.Buffer)
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		_ = html.Render(buf, c)
+	}
+
+	return buf.String()
+}
+
+// collapse trims and squashes whitespace sequences into single spaces.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
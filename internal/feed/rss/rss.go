@@ -0,0 +1,181 @@
+// Package rss parses RSS 2.0 (and compatible RSS 0.9x/1.0) documents into jf2
+// entries.
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+)
+
+type (
+	// Format parses RSS documents.
+	Format struct{}
+
+	document struct {
+		XMLName xml.Name
+		Channel channel `xml:"channel"`
+		Items   []item  `xml:"item"` // RSS 1.0 keeps items outside of channel
+	}
+
+	channel struct {
+		Title string `xml:"title"`
+		Image struct {
+			URL string `xml:"url"`
+		} `xml:"image"`
+		Items []item `xml:"item"`
+	}
+
+	item struct {
+		Title       string       `xml:"title"`
+		Link        string       `xml:"link"`
+		Description string       `xml:"description"`
+		Encoded     string       `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		Author      string       `xml:"author"`
+		Creator     string       `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		PubDate     string       `xml:"pubDate"`
+		Date        string       `xml:"http://purl.org/dc/elements/1.1/ date"`
+		GUID        string       `xml:"guid"`
+		Categories  []string     `xml:"category"`
+		Enclosures  []enclosure  `xml:"enclosure"`
+		Media       []feed.Media `xml:"http://search.yahoo.com/mrss/ content"`
+		Groups      []struct {
+			Media []feed.Media `xml:"http://search.yahoo.com/mrss/ content"`
+		} `xml:"http://search.yahoo.com/mrss/ group"`
+		Thumbnails []feed.Media `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	}
+
+	enclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	}
+)
+
+func (Format) Sniff(contentType string, body []byte) bool {
+	switch feed.MediaType(contentType) {
+	case "application/rss+xml", "application/rdf+xml":
+		return true
+	case "", "application/xml", "text/xml", "text/plain", "application/octet-stream":
+		root, ok := feed.XMLRoot(body)
+
+		return ok && (root.Local == "rss" || root.Local == "RDF")
+	default:
+		return false
+	}
+}
+
+func (Format) Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	doc := new(document)
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	if err := decoder.Decode(doc); err != nil {
+		return nil, nil, fmt.Errorf("cannot decode RSS document: %w", err)
+	}
+
+	out := &domain.Feed{
+		URL:   base,
+		Name:  strings.TrimSpace(doc.Channel.Title),
+		Photo: feed.Resolve(base, doc.Channel.Image.URL),
+	}
+
+	items := append(doc.Channel.Items, doc.Items...)
+	entries := make([]domain.Entry, 0, len(items))
+
+	for i := range items {
+		entries = append(entries, items[i].entry(base))
+	}
+
+	return out, entries, nil
+}
+
+func (i item) entry(base *url.URL) domain.Entry {
+	out := domain.Entry{
+		UID:       strings.TrimSpace(i.GUID),
+		URL:       feed.Resolve(base, i.Link),
+		Name:      strings.TrimSpace(i.Title),
+		Published: feed.ParseTime(i.PubDate),
+	}
+
+	if out.Published.IsZero() {
+		out.Published = feed.ParseTime(i.Date)
+	}
+
+	if out.UID == "" {
+		out.UID = out.URL
+	}
+
+	content, summary := i.Encoded, ""
+	if content == "" {
+		content = i.Description
+	} else {
+		summary = i.Description
+	}
+
+	if content = strings.TrimSpace(content); content != "" {
+		out.Content = &domain.Content{
+			Text: feed.PlainText(content),
+			HTML: content,
+		}
+	}
+
+	if summary = strings.TrimSpace(summary); summary != "" {
+		out.Summary = feed.PlainText(summary)
+	}
+
+	// microblog items may repeat the whole or truncated content as title
+	if out.Content != nil && out.Name != "" {
+		if title := strings.TrimRight(out.Name, ".… "); out.Name == out.Content.Text ||
+			(title != out.Name && strings.HasPrefix(out.Content.Text, title)) {
+			out.Name = ""
+		}
+	}
+
+	if name := strings.TrimSpace(i.Creator); name != "" {
+		out.Author = &domain.Card{Type: "card", Name: name}
+	} else if author := strings.TrimSpace(i.Author); author != "" {
+		out.Author = &domain.Card{Type: "card", Name: parseAuthor(author)}
+	}
+
+	for _, c := range i.Categories {
+		if c = strings.TrimSpace(c); c != "" {
+			out.Category = append(out.Category, c)
+		}
+	}
+
+	objects := append(append(make([]feed.Media, 0), i.Media...), i.Thumbnails...)
+	for _, g := range i.Groups {
+		objects = append(objects, g.Media...)
+	}
+
+	for _, e := range i.Enclosures {
+		objects = append(objects, e.media())
+	}
+
+	feed.AttachMedia(&out, base, objects...)
+
+	return out
+}
+
+func (e enclosure) media() feed.Media {
+	return feed.Media{URL: e.URL, Type: e.Type}
+}
+
+// parseAuthor extracts the name from 'email@example.com (Name)' RSS author
+// format.
+func parseAuthor(src string) string {
+	if _, name, found := strings.Cut(src, "("); found {
+		return strings.TrimSpace(strings.TrimSuffix(name, ")"))
+	}
+
+	return src
+}
@@ -0,0 +1,64 @@
+package rss_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed/rss"
+)
+
+func TestFormat_Parse(t *testing.T) {
+	t.Parallel()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "rss.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, _ := url.Parse("https://podcast.example.com/feed.xml")
+
+	feed, entries, err := rss.Format{}.Parse(body, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(&domain.Feed{
+		URL:   base,
+		Name:  "Example Podcast",
+		Photo: "https://podcast.example.com/cover.png",
+	}, feed); diff != "" {
+		t.Error(diff)
+	}
+
+	expect := []domain.Entry{{
+		UID:       "episode-1",
+		URL:       "https://podcast.example.com/1",
+		Name:      "Episode 1",
+		Summary:   "Short summary",
+		Published: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.FixedZone("", 0)),
+		Author:    &domain.Card{Type: "card", Name: "Jane Doe"},
+		Content:   &domain.Content{Text: "Full notes", HTML: "<p>Full <b>notes</b></p>"},
+		Category:  []string{"podcast"},
+		Photo:     []string{"https://podcast.example.com/1.jpg"},
+		Audio:     []string{"https://podcast.example.com/1.mp3"},
+	}, {
+		UID:       "https://podcast.example.com/2",
+		URL:       "https://podcast.example.com/2",
+		Published: time.Date(2023, time.March, 2, 8, 30, 0, 0, time.FixedZone("GMT", 0)),
+		Author:    &domain.Card{Type: "card", Name: "Jane Doe"},
+		Content: &domain.Content{
+			Text: "Hello from the microblog with a longer text",
+			HTML: "Hello from the microblog with a longer text",
+		},
+	}}
+
+	if diff := cmp.Diff(expect, entries); diff != "" {
+		t.Error(diff)
+	}
+}
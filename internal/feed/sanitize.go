@@ -0,0 +1,181 @@
+package feed
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// elements contains allowed HTML elements with their allowed attributes.
+// Other elements are replaced by their content.
+var elements = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"abbr":       {"title": true},
+	"audio":      {"src": true, "controls": true},
+	"b":          {},
+	"blockquote": {"cite": true},
+	"br":         {},
+	"cite":       {},
+	"code":       {},
+	"dd":         {},
+	"del":        {"cite": true, "datetime": true},
+	"details":    {},
+	"div":        {},
+	"dl":         {},
+	"dt":         {},
+	"em":         {},
+	"figcaption": {},
+	"figure":     {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"ins":        {"cite": true, "datetime": true},
+	"kbd":        {},
+	"li":         {},
+	"mark":       {},
+	"ol":         {"start": true, "reversed": true},
+	"p":          {},
+	"pre":        {},
+	"q":          {"cite": true},
+	"s":          {},
+	"samp":       {},
+	"small":      {},
+	"source":     {"src": true, "type": true},
+	"span":       {},
+	"strong":     {},
+	"sub":        {},
+	"summary":    {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"colspan": true, "rowspan": true},
+	"tfoot":      {},
+	"th":         {"colspan": true, "rowspan": true},
+	"thead":      {},
+	"time":       {"datetime": true},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+	"video":      {"src": true, "poster": true, "controls": true},
+}
+
+// dropped contains elements which are removed together with their content.
+var dropped = map[string]bool{
+	"embed": true, "form": true, "frame": true, "frameset": true, "iframe": true, "math": true,
+	"noscript": true, "object": true, "script": true, "select": true, "style": true, "svg": true,
+	"template": true, "textarea": true, "title": true,
+}
+
+// voids contains allowed elements without closing tag.
+var voids = map[string]bool{
+	"br": true, "hr": true, "img": true, "source": true,
+}
+
+// links contains attributes with URL values.
+var links = map[string]bool{
+	"cite": true, "href": true, "poster": true, "src": true,
+}
+
+// SanitizeHTML returns markup of src which contains only allowed elements and
+// attributes, so it can be safely embedded into clients pages. URLs are
+// resolved against base, links with schemes other than http, https and mailto
+// are removed.
+func SanitizeHTML(src string, base *url.URL) string {
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return ""
+	}
+
+	buf := new(strings.Builder)
+	for _, n := range nodes {
+		sanitize(buf, n, base)
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+func sanitize(buf *strings.Builder, n *html.Node, base *url.URL) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(n.Data))
+
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if dropped[n.Data] {
+		return
+	}
+
+	attrs, ok := elements[n.Data]
+	if !ok {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			sanitize(buf, c, base)
+		}
+
+		return
+	}
+
+	buf.WriteString("<" + n.Data)
+
+	for _, attr := range n.Attr {
+		if attr.Namespace != "" || !attrs[attr.Key] {
+			continue
+		}
+
+		value := attr.Val
+		if links[attr.Key] {
+			if value, ok = safeURL(base, value); !ok {
+				continue
+			}
+		}
+
+		buf.WriteString(" " + attr.Key + `="` + html.EscapeString(value) + `"`)
+	}
+
+	buf.WriteByte('>')
+
+	if voids[n.Data] {
+		return
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sanitize(buf, c, base)
+	}
+
+	buf.WriteString("</" + n.Data + ">")
+}
+
+// safeURL returns ref resolved against base if it points to a web page or an
+// email address.
+func safeURL(base *url.URL, ref string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", false
+	}
+
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return u.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,53 @@
+package feed_test
+
+import (
+	"net/url"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/feed"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	t.Parallel()
+
+	base, _ := url.Parse("https://example.com/posts/")
+
+	for name, tc := range map[string]struct {
+		input, expect string
+	}{
+		"allowed": {
+			input:  `<p>Hello, <strong>world</strong>!<br></p>`,
+			expect: `<p>Hello, <strong>world</strong>!<br></p>`,
+		},
+		"script": {
+			input:  `<p>Hi</p><script>alert(1)</script><style>p{}</style>`,
+			expect: `<p>Hi</p>`,
+		},
+		"handlers": {
+			input:  `<img src="cat.jpg" onerror="alert(1)" alt="Cat" style="width:1px">`,
+			expect: `<img src="https://example.com/posts/cat.jpg" alt="Cat">`,
+		},
+		"javascript link": {
+			input:  `<a href="javascript:alert(1)">click</a>`,
+			expect: `<a>click</a>`,
+		},
+		"unknown element": {
+			input:  `<custom-card class="x"><em>text</em></custom-card><iframe src="https://evil.example/"></iframe>`,
+			expect: `<em>text</em>`,
+		},
+		"escaped text": {
+			input:  `a &lt;b&gt; &amp; c`,
+			expect: `a &lt;b&gt; &amp; c`,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := feed.SanitizeHTML(tc.input, base); actual != tc.expect {
+				t.Errorf("want %s, got %s", tc.expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+// Package fetcher periodically downloads followed feeds and delivers their new
+// entries into subscribed channels.
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+)
+
+type (
+	Fetcher struct {
+		client     *http.Client
+		parser     *feed.Parser
+		follows    follow.Repository
+		timelines  timeline.UseCase
+		logger     *log.Logger
+		mutex      *sync.Mutex
+		sources    map[string]*source
+		interval   time.Duration
+		maxBackoff time.Duration
+		workers    int
+	}
+
+	NewFetcherOptions struct {
+		Client    *http.Client
+		Parser    *feed.Parser
+		Follows   follow.Repository
+		Timelines timeline.UseCase
+		Logger    *log.Logger
+
+		// Interval is a delay between successful fetches of the same
+		// source.
+		Interval time.Duration
+
+		// MaxBackoff limits exponential delay between fetches of failing
+		// source.
+		MaxBackoff time.Duration
+
+		// Workers is a maximum number of concurrent fetches.
+		Workers int
+	}
+
+	// source contains fetching schedule and conditional request state of
+	// a single followed URL.
+	source struct {
+		next         time.Time
+		etag         string
+		lastModified string
+		failures     int
+	}
+
+	job struct {
+		url           *url.URL
+		subscriptions []domain.Subscription
+	}
+)
+
+const (
+	DefaultInterval   time.Duration = 15 * time.Minute
+	DefaultMaxBackoff time.Duration = 24 * time.Hour
+	DefaultWorkers    int           = 4
+
+	// maxBodySize limits size of downloaded documents.
+	maxBodySize int64 = 8 << 20
+
+	acceptFeeds string = "application/feed+json, application/atom+xml, application/rss+xml, " +
+		"text/html;q=0.9, application/xml;q=0.8, */*;q=0.1"
+)
+
+var ErrStatus = errors.New("unexpected response status")
+
+func NewFetcher(opts NewFetcherOptions) *Fetcher {
+	out := &Fetcher{
+		client:     opts.Client,
+		parser:     opts.Parser,
+		follows:    opts.Follows,
+		timelines:  opts.Timelines,
+		logger:     opts.Logger,
+		mutex:      new(sync.Mutex),
+		sources:    make(map[string]*source),
+		interval:   opts.Interval,
+		maxBackoff: opts.MaxBackoff,
+		workers:    opts.Workers,
+	}
+
+	// followed URLs are user input, so they must not reach private
+	// networks by default
+	if out.client == nil {
+		out.client = httpclient.New(httpclient.Options{})
+	}
+
+	if out.parser == nil {
+		out.parser = feed.NewParser()
+	}
+
+	if out.logger == nil {
+		out.logger = log.New(io.Discard, "", 0)
+	}
+
+	if out.interval <= 0 {
+		out.interval = DefaultInterval
+	}
+
+	if out.maxBackoff <= 0 {
+		out.maxBackoff = DefaultMaxBackoff
+	}
+
+	if out.workers <= 0 {
+		out.workers = DefaultWorkers
+	}
+
+	return out
+}
+
+// Run polls due sources on every tick until ctx is done.
+func (f *Fetcher) Run(ctx context.Context, tick time.Duration) error {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		if err := f.Poll(ctx); err != nil {
+			f.logger.Println("cannot poll followed feeds:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches all followed sources which are due at the moment and waits
+// until all of them are processed.
+func (f *Fetcher) Poll(ctx context.Context) error {
+	subscriptions, err := f.follows.FetchAll(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot fetch subscriptions: %w", err)
+	}
+
+	jobs := make(map[string]*job)
+
+	for _, s := range subscriptions {
+		key := s.Feed.URL.String()
+		if _, ok := jobs[key]; !ok {
+			jobs[key] = &job{url: s.Feed.URL}
+		}
+
+		jobs[key].subscriptions = append(jobs[key].subscriptions, s)
+	}
+
+	now := time.Now()
+	queue := make(chan *job)
+	wg := new(sync.WaitGroup)
+
+	for i := 0; i < f.workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range queue {
+				f.poll(ctx, j)
+			}
+		}()
+	}
+
+	f.mutex.Lock()
+	for key := range f.sources {
+		if _, ok := jobs[key]; !ok {
+			delete(f.sources, key)
+		}
+	}
+
+	due := make([]*job, 0, len(jobs))
+
+	for key, j := range jobs {
+		if _, ok := f.sources[key]; !ok {
+			f.sources[key] = new(source)
+		}
+
+		if f.sources[key].next.After(now) {
+			continue
+		}
+
+		due = append(due, j)
+	}
+	f.mutex.Unlock()
+
+	for _, j := range due {
+		select {
+		case queue <- j:
+		case <-ctx.Done():
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// Fetch unconditionally downloads and parses the document by provided URL.
+func (f *Fetcher) Fetch(ctx context.Context, u *url.URL) (*domain.Feed, []domain.Entry, error) {
+	resp, err := f.do(ctx, u, new(source))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	return f.parse(resp)
+}
+
+func (f *Fetcher) poll(ctx context.Context, j *job) {
+	key := j.url.String()
+
+	f.mutex.Lock()
+	state := *f.sources[key]
+	f.mutex.Unlock()
+
+	resp, err := f.do(ctx, j.url, &state)
+	if err != nil {
+		f.fail(key, state, 0, err)
+
+		return
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		f.succeed(key, state)
+
+		return
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		f.fail(key, state, time.Duration(retryAfter)*time.Second, fmt.Errorf("%w: %s", ErrStatus, resp.Status))
+
+		return
+	case resp.StatusCode < 200, resp.StatusCode > 299:
+		f.fail(key, state, 0, fmt.Errorf("%w: %s", ErrStatus, resp.Status))
+
+		return
+	}
+
+	meta, entries, err := f.parse(resp)
+	if err != nil {
+		f.fail(key, state, 0, err)
+
+		return
+	}
+
+	state.etag = resp.Header.Get("ETag")
+	state.lastModified = resp.Header.Get("Last-Modified")
+	f.succeed(key, state)
+
+	for i := range entries {
+		entries[i].Source = key
+	}
+
+	for _, s := range j.subscriptions {
+		f.describe(ctx, s, *meta)
+
+		if _, err = f.timelines.Create(ctx, s.User, s.Channel, entries...); err != nil {
+			f.logger.Printf("cannot deliver entries of %s into %s channel of %s: %s", key, s.Channel, s.User,
+				err)
+		}
+	}
+}
+
+// describe keeps name and photo of followed feed in sync with the fetched
+// document, so clients show them in the list of follows.
+func (f *Fetcher) describe(ctx context.Context, s domain.Subscription, meta domain.Feed) {
+	name, photo := s.Feed.Name, s.Feed.Photo
+	if meta.Name != "" {
+		name = meta.Name
+	}
+
+	if meta.Photo != "" {
+		photo = meta.Photo
+	}
+
+	if name == s.Feed.Name && photo == s.Feed.Photo {
+		return
+	}
+
+	if err := f.follows.Update(ctx, s.User, s.Channel, s.Feed.URL, func(tx *domain.Feed) (*domain.Feed, error) {
+		tx.Name, tx.Photo = name, photo
+
+		return tx, nil
+	}); err != nil && !errors.Is(err, follow.ErrNotExist) {
+		f.logger.Printf("cannot update %s feed in %s channel of %s: %s", s.Feed.URL, s.Channel, s.User, err)
+	}
+}
+
+func (f *Fetcher) do(ctx context.Context, u *url.URL, state *source) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+
+	req.Header.Set("Accept", acceptFeeds)
+
+	if state.etag != "" {
+		req.Header.Set("If-None-Match", state.etag)
+	}
+
+	if state.lastModified != "" {
+		req.Header.Set("If-Modified-Since", state.lastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch %s: %w", u, err)
+	}
+
+	return resp, nil
+}
+
+func (f *Fetcher) parse(resp *http.Response) (*domain.Feed, []domain.Entry, error) {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrStatus, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read response body: %w", err)
+	}
+
+	// resolve relative links against the final URL after all redirects
+	return f.parser.Parse(resp.Header.Get("Content-Type"), body, resp.Request.URL)
+}
+
+func (f *Fetcher) succeed(key string, state source) {
+	state.failures = 0
+	state.next = time.Now().Add(f.interval)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.sources[key]; ok {
+		*f.sources[key] = state
+	}
+}
+
+// fail schedules next fetch of failed source with exponential backoff or after
+// delay requested by remote server.
+func (f *Fetcher) fail(key string, state source, retryAfter time.Duration, err error) {
+	f.logger.Printf("cannot fetch %s: %s", key, err)
+
+	state.failures++
+
+	backoff := f.interval
+	for i := 0; i < state.failures && backoff < f.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff < retryAfter {
+		backoff = retryAfter
+	}
+
+	if backoff > f.maxBackoff {
+		backoff = f.maxBackoff
+	}
+
+	state.next = time.Now().Add(backoff)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.sources[key]; ok {
+		*f.sources[key] = state
+	}
+}
@@ -0,0 +1,271 @@
+package fetcher_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+	channelucase "source.toby3d.me/toby3d/sub/internal/channel/usecase"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/fetcher"
+	followmemoryrepo "source.toby3d.me/toby3d/sub/internal/follow/repository/memory"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+	timelineucase "source.toby3d.me/toby3d/sub/internal/timeline/usecase"
+)
+
+// lines is a testing format which treats every line of plain text body as
+// entry UID.
+type lines struct{}
+
+func (lines) Sniff(contentType string, _ []byte) bool {
+	return strings.HasPrefix(contentType, "text/plain")
+}
+
+func (lines) Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	out := make([]domain.Entry, 0)
+
+	for _, line := range bytes.Fields(body) {
+		out = append(out, domain.Entry{UID: string(line)})
+	}
+
+	return &domain.Feed{URL: base}, out, nil
+}
+
+// named is a testing format which treats plain text body as feed name.
+type named struct{}
+
+func (named) Sniff(contentType string, _ []byte) bool {
+	return strings.HasPrefix(contentType, "text/plain")
+}
+
+func (named) Parse(body []byte, base *url.URL) (*domain.Feed, []domain.Entry, error) {
+	return &domain.Feed{
+		URL:   base,
+		Name:  strings.TrimSpace(string(body)),
+		Photo: base.JoinPath("photo.jpg").String(),
+	}, nil, nil
+}
+
+func TestFetcher_Poll(t *testing.T) {
+	t.Parallel()
+
+	var requests, conditional int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("one\ntwo\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	follows := followmemoryrepo.NewMemoryFollowRepository()
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{Entries: entries})
+
+	u, _ := url.Parse(srv.URL)
+	if err := follows.Create(context.Background(), *user, channel.UID, domain.Feed{URL: u}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := fetcher.NewFetcher(fetcher.NewFetcherOptions{
+		Client:    srv.Client(),
+		Parser:    feed.NewParser(lines{}),
+		Follows:   follows,
+		Timelines: timelines,
+		Interval:  time.Nanosecond,
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := f.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if actual := atomic.LoadInt32(&requests); actual != 2 {
+		t.Errorf("expect 2 requests, got %d", actual)
+	}
+
+	if actual := atomic.LoadInt32(&conditional); actual != 1 {
+		t.Errorf("expect 1 conditional request, got %d", actual)
+	}
+
+	result, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("expect 2 entries, got %d", len(result))
+	}
+
+	for _, e := range result {
+		if e.Source != srv.URL {
+			t.Errorf("expect %s source, got %s", srv.URL, e.Source)
+		}
+	}
+}
+
+func TestFetcher_Poll_Backoff(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	user := domain.TestUser(t)
+	follows := followmemoryrepo.NewMemoryFollowRepository()
+
+	u, _ := url.Parse(srv.URL)
+	if err := follows.Create(context.Background(), *user, "home", domain.Feed{URL: u}); err != nil {
+		t.Fatal(err)
+	}
+
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+
+	f := fetcher.NewFetcher(fetcher.NewFetcherOptions{
+		Client:    srv.Client(),
+		Follows:   follows,
+		Timelines: timelines,
+		Interval:  time.Hour,
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := f.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if actual := atomic.LoadInt32(&requests); actual != 1 {
+		t.Errorf("expect 1 request before backoff delay, got %d", actual)
+	}
+}
+
+func TestFetcher_Poll_DeletedChannel(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("one\ntwo\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	follows := followmemoryrepo.NewMemoryFollowRepository()
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{Entries: entries})
+
+	if err := channels.Create(context.Background(), *user, *channel); err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(srv.URL)
+	if err := follows.Create(context.Background(), *user, channel.UID, domain.Feed{URL: u}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := fetcher.NewFetcher(fetcher.NewFetcherOptions{
+		Client:    srv.Client(),
+		Parser:    feed.NewParser(lines{}),
+		Follows:   follows,
+		Timelines: timelines,
+		Interval:  time.Nanosecond,
+	})
+
+	if err := f.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+		Channels: channels,
+		Follows:  follows,
+		Entries:  entries,
+	}).Delete(context.Background(), *user, channel.UID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := atomic.LoadInt32(&requests); actual != 1 {
+		t.Errorf("expect 1 request before channel deletion, got %d", actual)
+	}
+
+	result, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 0 {
+		t.Errorf("expect no entries in deleted channel, got %+v", result)
+	}
+}
+
+func TestFetcher_Poll_Metadata(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("Example feed\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	follows := followmemoryrepo.NewMemoryFollowRepository()
+
+	u, _ := url.Parse(srv.URL)
+	if err := follows.Create(context.Background(), *user, channel.UID, domain.Feed{URL: u}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fetcher.NewFetcher(fetcher.NewFetcherOptions{
+		Client:  srv.Client(),
+		Parser:  feed.NewParser(named{}),
+		Follows: follows,
+		Timelines: timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+			Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+		}),
+	}).Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := follows.Get(context.Background(), *user, channel.UID, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.Name != "Example feed" || actual.Photo != srv.URL+"/photo.jpg" {
+		t.Errorf("expect followed feed with name and photo, got %+v", actual)
+	}
+}
@@ -0,0 +1,29 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	UpdateFunc func(feed *domain.Feed) (*domain.Feed, error)
+
+	Repository interface {
+		Create(ctx context.Context, user domain.User, channel string, feed domain.Feed) error
+		Get(ctx context.Context, user domain.User, channel string, u *url.URL) (*domain.Feed, error)
+		Fetch(ctx context.Context, user domain.User, channel string) ([]domain.Feed, error)
+		Update(ctx context.Context, user domain.User, channel string, u *url.URL, update UpdateFunc) error
+		Delete(ctx context.Context, user domain.User, channel string, u *url.URL) error
+
+		// FetchAll returns subscriptions of all users in all channels.
+		FetchAll(ctx context.Context) ([]domain.Subscription, error)
+	}
+)
+
+var (
+	ErrNotExist = errors.New("feed is not followed")
+	ErrExist    = errors.New("feed already followed")
+)
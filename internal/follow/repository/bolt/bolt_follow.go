@@ -0,0 +1,251 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+
+	database "source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+)
+
+type (
+	boltFollowRepository struct {
+		db *bolt.DB
+	}
+
+	// Feed is a stored value of followed feed.
+	Feed struct {
+		URL   string `json:"url"`
+		Name  string `json:"name,omitempty"`
+		Photo string `json:"photo,omitempty"`
+	}
+)
+
+// bucketFollows contains nested bucket of followed feeds by their URL for each
+// channel.
+var bucketFollows = []byte("follows")
+
+// NewBoltFollowRepository creates follows repository in database opened by
+// bolt.Open.
+func NewBoltFollowRepository(db *bolt.DB) follow.Repository {
+	return &boltFollowRepository{
+		db: db,
+	}
+}
+
+func (repo *boltFollowRepository) Create(ctx context.Context, u domain.User, cid string, f domain.Feed) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		feeds, err := channelBucket(tx, u, cid)
+		if err != nil {
+			return err
+		}
+
+		key := []byte(f.URL.String())
+		if feeds.Get(key) != nil {
+			return follow.ErrExist
+		}
+
+		src, err := json.Marshal(NewFeed(f))
+		if err != nil {
+			return fmt.Errorf("cannot encode feed: %w", err)
+		}
+
+		return feeds.Put(key, src)
+	})
+}
+
+func (repo *boltFollowRepository) Get(ctx context.Context, u domain.User, cid string, src *url.URL) (*domain.Feed, error) {
+	var out *domain.Feed
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		feeds, err := channelBucket(tx, u, cid)
+		if err != nil {
+			return err
+		}
+
+		if feeds == nil {
+			return follow.ErrNotExist
+		}
+
+		value := feeds.Get([]byte(src.String()))
+		if value == nil {
+			return follow.ErrNotExist
+		}
+
+		out, err = decode(value)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (repo *boltFollowRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Feed, error) {
+	out := make([]domain.Feed, 0)
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		feeds, err := channelBucket(tx, u, cid)
+		if err != nil || feeds == nil {
+			return err
+		}
+
+		return feeds.ForEach(func(_, value []byte) error {
+			f, err := decode(value)
+			if err != nil {
+				return err
+			}
+
+			out = append(out, *f)
+
+			return nil
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("cannot fetch followed feeds: %w", err)
+	}
+
+	return out, nil
+}
+
+func (repo *boltFollowRepository) Update(ctx context.Context, u domain.User, cid string, src *url.URL,
+	update follow.UpdateFunc,
+) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		feeds, err := channelBucket(tx, u, cid)
+		if err != nil {
+			return err
+		}
+
+		key := []byte(src.String())
+
+		value := feeds.Get(key)
+		if value == nil {
+			return fmt.Errorf("cannot find updating follow: %w", follow.ErrNotExist)
+		}
+
+		in, err := decode(value)
+		if err != nil {
+			return err
+		}
+
+		out, err := update(in)
+		if err != nil {
+			return fmt.Errorf("cannot update follow: %w", err)
+		}
+
+		// feed cannot be moved to another URL by update
+		out.URL = src
+
+		if value, err = json.Marshal(NewFeed(*out)); err != nil {
+			return fmt.Errorf("cannot encode feed: %w", err)
+		}
+
+		return feeds.Put(key, value)
+	})
+}
+
+func (repo *boltFollowRepository) Delete(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		feeds, err := channelBucket(tx, u, cid)
+		if err != nil {
+			return err
+		}
+
+		return feeds.Delete([]byte(src.String()))
+	})
+}
+
+func (repo *boltFollowRepository) FetchAll(ctx context.Context) ([]domain.Subscription, error) {
+	out := make([]domain.Subscription, 0)
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		return database.ForEachUser(tx, bucketFollows, func(me string, follows *bolt.Bucket) error {
+			u, err := url.Parse(me)
+			if err != nil {
+				return fmt.Errorf("cannot parse user of subscription: %w", err)
+			}
+
+			return follows.ForEach(func(cid, value []byte) error {
+				// follows bucket contains only nested buckets of channels
+				if value != nil {
+					return nil
+				}
+
+				return follows.Bucket(cid).ForEach(func(_, value []byte) error {
+					f, err := decode(value)
+					if err != nil {
+						return err
+					}
+
+					out = append(out, domain.Subscription{
+						User:    domain.User{URL: u},
+						Channel: string(cid),
+						Feed:    *f,
+					})
+
+					return nil
+				})
+			})
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("cannot fetch subscriptions: %w", err)
+	}
+
+	return out, nil
+}
+
+func NewFeed(f domain.Feed) *Feed {
+	return &Feed{
+		URL:   f.URL.String(),
+		Name:  f.Name,
+		Photo: f.Photo,
+	}
+}
+
+func (f Feed) Populate() (*domain.Feed, error) {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse feed URL: %w", err)
+	}
+
+	return &domain.Feed{
+		URL:   u,
+		Name:  f.Name,
+		Photo: f.Photo,
+	}, nil
+}
+
+// channelBucket returns bucket of feeds followed in user channel. It returns
+// nil in read-only transaction if there are no such feeds yet.
+func channelBucket(tx *bolt.Tx, u domain.User, cid string) (*bolt.Bucket, error) {
+	follows, err := database.UserBucket(tx, u, bucketFollows)
+	if err != nil || follows == nil {
+		return nil, err
+	}
+
+	if !tx.Writable() {
+		return follows.Bucket([]byte(cid)), nil
+	}
+
+	out, err := follows.CreateBucketIfNotExists([]byte(cid))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create channel bucket: %w", err)
+	}
+
+	return out, nil
+}
+
+func decode(src []byte) (*domain.Feed, error) {
+	f := new(Feed)
+	if err := json.Unmarshal(src, f); err != nil {
+		return nil, fmt.Errorf("cannot decode feed: %w", err)
+	}
+
+	return f.Populate()
+}
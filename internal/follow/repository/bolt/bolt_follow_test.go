@@ -0,0 +1,91 @@
+package bolt_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	repository "source.toby3d.me/toby3d/sub/internal/follow/repository/bolt"
+)
+
+func TestBoltFollowRepository(t *testing.T) {
+	t.Parallel()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "sub.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	feed := domain.TestFeed(t)
+	follows := repository.NewBoltFollowRepository(db)
+
+	if err = follows.Create(ctx, *user, "home", *feed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = follows.Create(ctx, *user, "home", *feed); !errors.Is(err, follow.ErrExist) {
+		t.Errorf("want %v, got %v", follow.ErrExist, err)
+	}
+
+	actual, err := follows.Get(ctx, *user, "home", feed.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.URL.String() != feed.URL.String() || actual.Name != feed.Name {
+		t.Errorf("want %+v, got %+v", feed, actual)
+	}
+
+	subscriptions, err := follows.FetchAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(subscriptions) != 1 || subscriptions[0].Channel != "home" ||
+		subscriptions[0].User.String() != user.String() {
+		t.Errorf("want single subscription of %s in home channel, got %+v", user, subscriptions)
+	}
+
+	if err = follows.Update(ctx, *user, "home", feed.URL, func(tx *domain.Feed) (*domain.Feed, error) {
+		tx.Name, tx.Photo = "Updated", "https://example.com/photo.jpg"
+
+		return tx, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual, err = follows.Get(ctx, *user, "home", feed.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.URL.String() != feed.URL.String() || actual.Name != "Updated" ||
+		actual.Photo != "https://example.com/photo.jpg" {
+		t.Errorf("want updated %s feed, got %+v", feed.URL, actual)
+	}
+
+	if err = follows.Update(ctx, *user, "other", feed.URL, func(tx *domain.Feed) (*domain.Feed, error) {
+		return tx, nil
+	}); !errors.Is(err, follow.ErrNotExist) {
+		t.Errorf("want %v, got %v", follow.ErrNotExist, err)
+	}
+
+	if err = follows.Delete(ctx, *user, "home", feed.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	feeds, err := follows.Fetch(ctx, *user, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feeds) != 0 {
+		t.Errorf("want no feeds after unfollow, got %+v", feeds)
+	}
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+)
+
+type memoryFollowRepository struct {
+	mutex *sync.RWMutex
+	feeds map[string]map[string][]domain.Feed
+}
+
+func NewMemoryFollowRepository() follow.Repository {
+	return &memoryFollowRepository{
+		mutex: new(sync.RWMutex),
+		feeds: make(map[string]map[string][]domain.Feed, 0),
+	}
+}
+
+func (repo *memoryFollowRepository) Create(ctx context.Context, u domain.User, cid string, f domain.Feed) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.index(u, cid, f.URL) != -1 {
+		return follow.ErrExist
+	}
+
+	if _, ok := repo.feeds[u.String()]; !ok {
+		repo.feeds[u.String()] = make(map[string][]domain.Feed)
+	}
+
+	repo.feeds[u.String()][cid] = append(repo.feeds[u.String()][cid], f)
+
+	return nil
+}
+
+func (repo *memoryFollowRepository) Get(ctx context.Context, u domain.User, cid string, src *url.URL) (*domain.Feed, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	i := repo.index(u, cid, src)
+	if i == -1 {
+		return nil, follow.ErrNotExist
+	}
+
+	out := repo.feeds[u.String()][cid][i]
+
+	return &out, nil
+}
+
+func (repo *memoryFollowRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Feed, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	return append(make([]domain.Feed, 0), repo.feeds[u.String()][cid]...), nil
+}
+
+func (repo *memoryFollowRepository) Update(ctx context.Context, u domain.User, cid string, src *url.URL,
+	update follow.UpdateFunc,
+) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	i := repo.index(u, cid, src)
+	if i == -1 {
+		return fmt.Errorf("cannot find updating follow: %w", follow.ErrNotExist)
+	}
+
+	in := repo.feeds[u.String()][cid][i]
+
+	out, err := update(&in)
+	if err != nil {
+		return fmt.Errorf("cannot update follow: %w", err)
+	}
+
+	// feed cannot be moved to another URL by update
+	out.URL = src
+	repo.feeds[u.String()][cid][i] = *out
+
+	return nil
+}
+
+func (repo *memoryFollowRepository) Delete(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if i := repo.index(u, cid, src); i != -1 {
+		repo.feeds[u.String()][cid] = slices.Delete(repo.feeds[u.String()][cid], i, i+1)
+	}
+
+	return nil
+}
+
+func (repo *memoryFollowRepository) FetchAll(ctx context.Context) ([]domain.Subscription, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	out := make([]domain.Subscription, 0)
+
+	for me, channels := range repo.feeds {
+		u, err := url.Parse(me)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse user of subscription: %w", err)
+		}
+
+		for cid, feeds := range channels {
+			for _, f := range feeds {
+				out = append(out, domain.Subscription{
+					User:    domain.User{URL: u},
+					Channel: cid,
+					Feed:    f,
+				})
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// index returns position of followed feed in user channel or -1 if there is
+// none. Must be called under lock.
+func (repo *memoryFollowRepository) index(u domain.User, cid string, src *url.URL) int {
+	feeds := repo.feeds[u.String()][cid]
+
+	for i := range feeds {
+		if feeds[i].URL.String() == src.String() {
+			return i
+		}
+	}
+
+	return -1
+}
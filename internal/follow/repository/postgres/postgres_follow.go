@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+)
+
+type (
+	postgresFollowRepository struct {
+		db *sqlx.DB
+	}
+
+	// Follow is a row of follows table.
+	Follow struct {
+		Me      string `db:"me"`
+		Channel string `db:"channel"`
+		URL     string `db:"url"`
+		Name    string `db:"name"`
+		Photo   string `db:"photo"`
+	}
+)
+
+const (
+	queryTable    string = "SELECT me, channel, url, name, photo FROM follows"
+	queryGet      string = queryTable + " WHERE me = $1 AND channel = $2 AND url = $3"
+	queryFetch    string = queryTable + " WHERE me = $1 AND channel = $2 ORDER BY url"
+	queryFetchAll string = queryTable + " ORDER BY me, channel, url"
+	queryCreate   string = `INSERT INTO follows (me, channel, url, name, photo)
+		VALUES (:me, :channel, :url, :name, :photo)
+		ON CONFLICT (me, channel, url) DO NOTHING`
+	queryUpdate string = `UPDATE follows SET name = :name, photo = :photo
+		WHERE me = :me AND channel = :channel AND url = :url`
+	queryDelete string = "DELETE FROM follows WHERE me = $1 AND channel = $2 AND url = $3"
+)
+
+// NewPostgresFollowRepository creates follows repository in database opened by
+// postgres.Open.
+func NewPostgresFollowRepository(db *sqlx.DB) follow.Repository {
+	return &postgresFollowRepository{
+		db: db,
+	}
+}
+
+func (repo *postgresFollowRepository) Create(ctx context.Context, u domain.User, cid string, f domain.Feed) error {
+	result, err := repo.db.NamedExecContext(ctx, queryCreate, NewFollow(u, cid, f))
+	if err != nil {
+		return fmt.Errorf("cannot create follow: %w", err)
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return follow.ErrExist
+	}
+
+	return nil
+}
+
+func (repo *postgresFollowRepository) Get(ctx context.Context, u domain.User, cid string, src *url.URL) (*domain.Feed, error) {
+	row := new(Follow)
+	if err := repo.db.GetContext(ctx, row, queryGet, u.String(), cid, src.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, follow.ErrNotExist
+		}
+
+		return nil, fmt.Errorf("cannot get follow: %w", err)
+	}
+
+	return row.Populate()
+}
+
+func (repo *postgresFollowRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Feed, error) {
+	rows := make([]Follow, 0)
+	if err := repo.db.SelectContext(ctx, &rows, queryFetch, u.String(), cid); err != nil {
+		return nil, fmt.Errorf("cannot fetch follows: %w", err)
+	}
+
+	out := make([]domain.Feed, 0, len(rows))
+
+	for i := range rows {
+		f, err := rows[i].Populate()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, *f)
+	}
+
+	return out, nil
+}
+
+func (repo *postgresFollowRepository) Update(ctx context.Context, u domain.User, cid string, src *url.URL,
+	update follow.UpdateFunc,
+) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// lock the row until commit, so concurrent updates do not overwrite
+	// each other
+	row := new(Follow)
+	if err = tx.GetContext(ctx, row, queryGet+" FOR UPDATE", u.String(), cid, src.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("cannot find updating follow: %w", follow.ErrNotExist)
+		}
+
+		return fmt.Errorf("cannot get updating follow: %w", err)
+	}
+
+	in, err := row.Populate()
+	if err != nil {
+		return err
+	}
+
+	out, err := update(in)
+	if err != nil {
+		return fmt.Errorf("cannot update follow: %w", err)
+	}
+
+	// feed cannot be moved to another URL by update
+	out.URL = src
+
+	if _, err = tx.NamedExecContext(ctx, queryUpdate, NewFollow(u, cid, *out)); err != nil {
+		return fmt.Errorf("cannot update follow: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("cannot commit updated follow: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *postgresFollowRepository) Delete(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	if _, err := repo.db.ExecContext(ctx, queryDelete, u.String(), cid, src.String()); err != nil {
+		return fmt.Errorf("cannot delete follow: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *postgresFollowRepository) FetchAll(ctx context.Context) ([]domain.Subscription, error) {
+	rows := make([]Follow, 0)
+	if err := repo.db.SelectContext(ctx, &rows, queryFetchAll); err != nil {
+		return nil, fmt.Errorf("cannot fetch subscriptions: %w", err)
+	}
+
+	out := make([]domain.Subscription, 0, len(rows))
+
+	for i := range rows {
+		me, err := url.Parse(rows[i].Me)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse user of subscription: %w", err)
+		}
+
+		f, err := rows[i].Populate()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, domain.Subscription{
+			User:    domain.User{URL: me},
+			Channel: rows[i].Channel,
+			Feed:    *f,
+		})
+	}
+
+	return out, nil
+}
+
+func NewFollow(u domain.User, cid string, f domain.Feed) *Follow {
+	return &Follow{
+		Me:      u.String(),
+		Channel: cid,
+		URL:     f.URL.String(),
+		Name:    f.Name,
+		Photo:   f.Photo,
+	}
+}
+
+func (f Follow) Populate() (*domain.Feed, error) {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse feed URL: %w", err)
+	}
+
+	return &domain.Feed{
+		URL:   u,
+		Name:  f.Name,
+		Photo: f.Photo,
+	}, nil
+}
@@ -0,0 +1,14 @@
+package follow
+
+import (
+	"context"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type UseCase interface {
+	Fetch(ctx context.Context, u domain.User, channel string) ([]domain.Feed, error)
+	Follow(ctx context.Context, u domain.User, channel string, feed *url.URL) (*domain.Feed, error)
+	Unfollow(ctx context.Context, u domain.User, channel string, feed *url.URL) error
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+)
+
+type followUseCase struct {
+	follows  follow.Repository
+	channels channel.Repository
+}
+
+func NewFollowUseCase(follows follow.Repository, channels channel.Repository) follow.UseCase {
+	return &followUseCase{
+		follows:  follows,
+		channels: channels,
+	}
+}
+
+func (ucase *followUseCase) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Feed, error) {
+	feeds, err := ucase.follows.Fetch(ctx, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch followed feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+func (ucase *followUseCase) Follow(ctx context.Context, u domain.User, cid string, src *url.URL) (*domain.Feed, error) {
+	if _, err := ucase.channels.Get(ctx, u, cid); err != nil {
+		return nil, fmt.Errorf("cannot find channel for follow: %w", err)
+	}
+
+	// following already followed feed is not an error for clients, so just
+	// return it as is
+	if err := ucase.follows.Create(ctx, u, cid, domain.Feed{URL: src}); err != nil && !errors.Is(err, follow.ErrExist) {
+		return nil, fmt.Errorf("cannot follow feed: %w", err)
+	}
+
+	out, err := ucase.follows.Get(ctx, u, cid, src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot return followed feed: %w", err)
+	}
+
+	return out, nil
+}
+
+func (ucase *followUseCase) Unfollow(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	if _, err := ucase.follows.Get(ctx, u, cid, src); err != nil {
+		return fmt.Errorf("cannot find unfollowing feed: %w", err)
+	}
+
+	if err := ucase.follows.Delete(ctx, u, cid, src); err != nil {
+		return fmt.Errorf("cannot unfollow feed: %w", err)
+	}
+
+	return nil
+}
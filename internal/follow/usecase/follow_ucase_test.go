@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	followmemoryrepo "source.toby3d.me/toby3d/sub/internal/follow/repository/memory"
+	ucase "source.toby3d.me/toby3d/sub/internal/follow/usecase"
+)
+
+func TestFollowUseCase_Follow(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	feed := domain.TestFeed(t)
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	if err := channels.Create(context.Background(), *user, *channel); err != nil {
+		t.Fatal(err)
+	}
+
+	follows := ucase.NewFollowUseCase(followmemoryrepo.NewMemoryFollowRepository(), channels)
+
+	for i := 0; i < 2; i++ {
+		actual, err := follows.Follow(context.Background(), *user, channel.UID, feed.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual.URL.String() != feed.URL.String() {
+			t.Errorf("expect %s, got %s", feed.URL, actual.URL)
+		}
+	}
+
+	actual, err := follows.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]domain.Feed{{URL: feed.URL}}, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestFollowUseCase_Unfollow(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	feed := domain.TestFeed(t)
+	follows := followmemoryrepo.NewMemoryFollowRepository()
+
+	if err := follows.Create(context.Background(), *user, channel.UID, *feed); err != nil {
+		t.Fatal(err)
+	}
+
+	followUseCase := ucase.NewFollowUseCase(follows, channelmemoryrepo.NewMemoryChannelRepository())
+
+	if err := followUseCase.Unfollow(context.Background(), *user, channel.UID, feed.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := followUseCase.Unfollow(context.Background(), *user, channel.UID, feed.URL); !errors.Is(err, follow.ErrNotExist) {
+		t.Errorf("expect %v, got %v", follow.ErrNotExist, err)
+	}
+}
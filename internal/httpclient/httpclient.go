@@ -0,0 +1,101 @@
+// Package httpclient provides HTTP client for requests to third-party URLs,
+// like feeds, search pages and Webmention sources, which must not reach
+// loopback and private networks of the server.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+type Options struct {
+	// Timeout limits time of each request including reading of the body.
+	// DefaultTimeout is used if zero.
+	Timeout time.Duration
+
+	// AllowPrivate lets requests reach loopback, private and link-local
+	// addresses, which are refused otherwise.
+	AllowPrivate bool
+}
+
+const DefaultTimeout time.Duration = 30 * time.Second
+
+var ErrPrivate = errors.New("address is not public")
+
+// privatePrefixes contains ranges which are not covered by netip.Addr methods
+// but are not reachable from the public Internet either.
+var privatePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// New returns client with timeout which checks every dialed address,
+// including addresses of redirects and resolved host names, so DNS cannot
+// be used to bypass the check.
+func New(opts Options) *http.Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   opts.Timeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if !opts.AllowPrivate {
+		dialer.Control = control
+
+		// proxy would be dialed instead of the target
+		transport.Proxy = nil
+	}
+
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}
+}
+
+// IsPublic reports whether addr is reachable from the public Internet.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range privatePrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("cannot parse dialed address: %w", err)
+	}
+
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivate, addrPort.Addr())
+	}
+
+	return nil
+}
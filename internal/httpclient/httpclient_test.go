@@ -0,0 +1,73 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	for name, tc := range map[string]struct {
+		opts   httpclient.Options
+		expect error
+	}{
+		"private": {opts: httpclient.Options{}, expect: httpclient.ErrPrivate},
+		"allowed": {opts: httpclient.Options{AllowPrivate: true}, expect: nil},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := httpclient.New(tc.opts).Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+
+			if !errors.Is(err, tc.expect) {
+				t.Errorf("want %v, got %v", tc.expect, err)
+			}
+		})
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	t.Parallel()
+
+	for input, expect := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::248": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+	} {
+		if actual := httpclient.IsPublic(netip.MustParseAddr(input)); actual != expect {
+			t.Errorf("%s: want %t, got %t", input, expect, actual)
+		}
+	}
+}
@@ -1,124 +1,623 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/goccy/go-json"
 
+	"source.toby3d.me/toby3d/sub/internal/auth"
+	"source.toby3d.me/toby3d/sub/internal/block"
 	"source.toby3d.me/toby3d/sub/internal/channel"
 	"source.toby3d.me/toby3d/sub/internal/common"
 	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/event"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	"source.toby3d.me/toby3d/sub/internal/mute"
+	"source.toby3d.me/toby3d/sub/internal/preview"
+	"source.toby3d.me/toby3d/sub/internal/search"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
 )
 
-type Handler struct {
-	channels channel.UseCase
-}
+type (
+	Handler struct {
+		channels  channel.UseCase
+		blocks    block.UseCase
+		events    event.Bus
+		follows   follow.UseCase
+		mutes     mute.UseCase
+		previews  preview.UseCase
+		search    search.UseCase
+		timelines timeline.UseCase
+		heartbeat time.Duration
+	}
+
+	NewHandlerOptions struct {
+		Channels  channel.UseCase
+		Blocks    block.UseCase
+		Events    event.Bus
+		Follows   follow.UseCase
+		Mutes     mute.UseCase
+		Previews  preview.UseCase
+		Search    search.UseCase
+		Timelines timeline.UseCase
+
+		// Heartbeat is an interval of keepalive comments in events
+		// stream. DefaultHeartbeat is used if zero.
+		Heartbeat time.Duration
+	}
+)
+
+// DefaultHeartbeat is a default interval of keepalive comments in events
+// stream, short enough to not be closed by proxies as idle.
+const DefaultHeartbeat time.Duration = 30 * time.Second
+
+func NewHandler(opts NewHandlerOptions) *Handler {
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = DefaultHeartbeat
+	}
 
-func NewHandler(channels channel.UseCase) *Handler {
 	return &Handler{
-		channels: channels,
+		channels:  opts.Channels,
+		blocks:    opts.Blocks,
+		events:    opts.Events,
+		follows:   opts.Follows,
+		mutes:     opts.Mutes,
+		previews:  opts.Previews,
+		search:    opts.Search,
+		timelines: opts.Timelines,
+		heartbeat: opts.Heartbeat,
 	}
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	user, _ := r.Context().Value("user").(*domain.User)
-	encoder := json.NewEncoder(w)
+	if user == nil {
+		WriteError(w, auth.ErrNoToken)
+
+		return
+	}
 
 	switch r.Method {
 	default:
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		WriteError(w, NewMethodError(r.Method))
 	case "", http.MethodGet:
 		action, err := domain.ParseAction(r.URL.Query().Get("action"))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			WriteError(w, err)
 
 			return
 		}
 
+		if !h.authorize(w, r, *user, action) {
+			return
+		}
+
 		switch action {
+		default:
+			WriteError(w, fmt.Errorf("%w: %s", domain.ErrActionSyntax, action))
 		case domain.ActionChannels:
-			channels, err := h.channels.Fetch(r.Context(), *user)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+			h.getChannels(w, r, *user)
+		case domain.ActionTimeline:
+			h.getTimeline(w, r, *user)
+		case domain.ActionFollow:
+			h.getFollow(w, r, *user)
+		case domain.ActionMute:
+			h.getMute(w, r, *user)
+		case domain.ActionBlock:
+			h.getBlock(w, r, *user)
+		case domain.ActionSearch:
+			h.searchFeeds(w, r)
+		case domain.ActionPreview:
+			h.preview(w, r)
+		case domain.ActionEvents:
+			h.getEvents(w, r, *user)
+		}
+	case http.MethodPost:
+		if err := parseBody(r); err != nil {
+			WriteError(w, err)
 
-				return
-			}
+			return
+		}
+
+		action, err := domain.ParseAction(r.PostFormValue("action"))
+		if err != nil {
+			WriteError(w, err)
 
-			w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
-			_ = encoder.Encode(NewResponseChannels(channels...))
+			return
 		}
-	case http.MethodPost:
-		if err := r.ParseForm(); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
 
+		if !h.authorize(w, r, *user, action) {
 			return
 		}
 
-		switch {
+		switch action {
 		default:
-			req := new(RequestChannelsCreate)
-			if err := req.bind(r); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+			WriteError(w, fmt.Errorf("%w: %s", domain.ErrActionSyntax, action))
+		case domain.ActionChannels:
+			h.postChannels(w, r, *user)
+		case domain.ActionTimeline:
+			h.postTimeline(w, r, *user)
+		case domain.ActionFollow:
+			h.postFollow(w, r, *user)
+		case domain.ActionUnfollow:
+			h.postUnfollow(w, r, *user)
+		case domain.ActionMute:
+			h.postMute(w, r, *user)
+		case domain.ActionUnmute:
+			h.postUnmute(w, r, *user)
+		case domain.ActionBlock:
+			h.postBlock(w, r, *user)
+		case domain.ActionUnblock:
+			h.postUnblock(w, r, *user)
+		case domain.ActionSearch:
+			h.searchFeeds(w, r)
+		case domain.ActionPreview:
+			h.preview(w, r)
+		}
+	}
+}
 
-				return
-			}
+// authorize checks that access token of user grants the scope required by
+// action and responds with insufficient_scope error otherwise. Unsupported
+// actions are passed through to be rejected by the caller.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, user domain.User, action domain.Action) bool {
+	scope := RequiredScope(r.Method, action)
+	if scope == domain.ScopeUnd || user.HasScope(scope) {
+		return true
+	}
 
-			result, err := h.channels.Create(r.Context(), *user, req.Name)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, scope))
+	WriteError(w, &Error{
+		err:    fmt.Errorf("'%s' action requires '%s' scope", action, scope),
+		Code:   ErrorCodeInsufficientScope,
+		Status: http.StatusForbidden,
+	})
 
-				return
-			}
+	return false
+}
+
+func (h *Handler) getChannels(w http.ResponseWriter, r *http.Request, user domain.User) {
+	channels, err := h.channels.Fetch(r.Context(), user)
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	unread := make(map[string]int, len(channels))
+
+	for i := range channels {
+		if unread[channels[i].UID], err = h.timelines.Unread(r.Context(), user, channels[i].UID); err != nil {
+			WriteError(w, err)
+
+			return
+		}
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseChannels(unread, channels...))
+}
+
+func (h *Handler) postChannels(w http.ResponseWriter, r *http.Request, user domain.User) {
+	encoder := json.NewEncoder(w)
+
+	switch {
+	default:
+		req := new(RequestChannelsCreate)
+		if err := req.bind(r); err != nil {
+			WriteError(w, newInvalidRequestError(err))
+
+			return
+		}
+
+		result, err := h.channels.Create(r.Context(), user, req.Name)
+		if err != nil {
+			WriteError(w, err)
+
+			return
+		}
+
+		w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+		_ = encoder.Encode(NewResponseChannel(result))
+	case r.PostForm.Has("channels[]"), r.PostForm.Has("channels"):
+		req := new(RequestChannelsOrder)
+		if err := req.bind(r); err != nil {
+			WriteError(w, newInvalidRequestError(err))
+
+			return
+		}
+
+		if err := h.channels.Order(r.Context(), user, req.Channel); err != nil {
+			WriteError(w, err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case r.PostForm.Has("method"):
+		req := new(RequestChannelsDelete)
+		if err := req.bind(r); err != nil {
+			WriteError(w, newInvalidRequestError(err))
+
+			return
+		}
+
+		if err := h.channels.Delete(r.Context(), user, req.Channel); err != nil {
+			WriteError(w, err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	case r.PostForm.Has("channel"):
+		req := new(RequestChannelsUpdate)
+		if err := req.bind(r); err != nil {
+			WriteError(w, newInvalidRequestError(err))
+
+			return
+		}
+
+		var (
+			result *domain.Channel
+			err    error
+		)
 
-			w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
-			_ = encoder.Encode(NewResponseChannel(result))
-		case r.PostForm.Has("method"):
-			req := new(RequestChannelsDelete)
-			if err := req.bind(r); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+		if req.Name != "" {
+			if result, err = h.channels.Update(r.Context(), user, req.Channel, req.Name); err != nil {
+				WriteError(w, err)
 
 				return
 			}
+		}
 
-			if err := h.channels.Delete(r.Context(), *user, req.Channel); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+		if req.Unread != domain.UnreadModeUnd {
+			if result, err = h.channels.SetUnread(r.Context(), user, req.Channel, req.Unread); err != nil {
+				WriteError(w, err)
 
 				return
 			}
+		}
 
-			w.WriteHeader(http.StatusNoContent)
-		case r.PostForm.Has("channels[]"):
-			req := new(RequestChannelsOrder)
-			if err := req.bind(r); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+		if req.Retention != nil || req.ResetRetention {
+			if result, err = h.channels.SetRetention(r.Context(), user, req.Channel, req.Retention); err != nil {
+				WriteError(w, err)
 
 				return
 			}
+		}
+
+		w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+		_ = encoder.Encode(NewResponseChannel(result))
+	}
+}
+
+func (h *Handler) getTimeline(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestTimelines)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	result, err := h.timelines.Fetch(r.Context(), user, req.Channel, domain.Paging{
+		After:  req.After,
+		Before: req.Before,
+	})
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseTimelines(result))
+}
+
+func (h *Handler) postTimeline(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestTimeline)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	var err error
+
+	switch req.Method {
+	case domain.MethodMarkRead:
+		if req.LastReadEntry != "" {
+			err = h.timelines.MarkReadBefore(r.Context(), user, req.Channel, req.LastReadEntry)
+		} else {
+			err = h.timelines.MarkRead(r.Context(), user, req.Channel, req.Entries...)
+		}
+	case domain.MethodMarkUnread:
+		err = h.timelines.MarkUnread(r.Context(), user, req.Channel, req.Entries...)
+	case domain.MethodRemove:
+		err = h.timelines.Remove(r.Context(), user, req.Channel, req.Entries...)
+	}
+
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getFollow(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestFollows)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
 
-			if err := h.channels.Order(r.Context(), *user, req.Channel); err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	feeds, err := h.follows.Fetch(r.Context(), user, req.Channel)
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseFollows(feeds...))
+}
+
+func (h *Handler) postFollow(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestFollow)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	result, err := h.follows.Follow(r.Context(), user, req.Channel, req.URL)
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseFeed(*result))
+}
+
+func (h *Handler) postUnfollow(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestUnfollow)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	if err := h.follows.Unfollow(r.Context(), user, req.Channel, req.URL); err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getMute(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestMutes)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	authors, err := h.mutes.Fetch(r.Context(), user, req.Channel)
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseMutes(authors...))
+}
+
+func (h *Handler) postMute(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestMute)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	result, err := h.mutes.Mute(r.Context(), user, req.Channel, req.URL)
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseAuthor(*result))
+}
+
+func (h *Handler) postUnmute(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestUnmute)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	if err := h.mutes.Unmute(r.Context(), user, req.Channel, req.URL); err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getBlock(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestBlocks)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	authors, err := h.blocks.Fetch(r.Context(), user, req.Channel)
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseBlocks(authors...))
+}
 
+func (h *Handler) postBlock(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestBlock)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	result, err := h.blocks.Block(r.Context(), user, req.Channel, req.URL)
+	if err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseAuthor(*result))
+}
+
+func (h *Handler) postUnblock(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestUnblock)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	if err := h.blocks.Unblock(r.Context(), user, req.Channel, req.URL); err != nil {
+		WriteError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getEvents(w http.ResponseWriter, r *http.Request, user domain.User) {
+	req := new(RequestEvents)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, errors.New("streaming is not supported"))
+
+		return
+	}
+
+	stream, unsubscribe := h.events.Subscribe(user)
+	defer unsubscribe()
+
+	w.Header().Set(common.HeaderContentType, common.MIMETextEventStream)
+	w.Header().Set(common.HeaderCacheControl, "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// send headers right now, so client knows that it's subscribed
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	encoder := json.NewEncoder(w)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-stream:
+			if !ok {
 				return
 			}
 
-			w.WriteHeader(http.StatusNoContent)
-		case r.PostForm.Has("channel"):
-			req := new(RequestChannelsUpdate)
-			if err := req.bind(r); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+			if req.Channel != "" && e.Channel.UID != req.Channel {
+				continue
+			}
 
+			// encoder ends data with a new line, so only one is needed
+			// to finish the event
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: ", e.Type); err != nil {
 				return
 			}
 
-			result, err := h.channels.Update(r.Context(), *user, req.Channel, req.Name)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-
+			if err := encoder.Encode(NewResponseEvent(e)); err != nil {
 				return
 			}
 
-			w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
-			_ = encoder.Encode(NewResponseChannel(result))
+			if _, err := fmt.Fprint(w, "\n"); err != nil {
+				return
+			}
 		}
+
+		flusher.Flush()
 	}
 }
+
+func (h *Handler) searchFeeds(w http.ResponseWriter, r *http.Request) {
+	req := new(RequestSearch)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	results, err := h.search.Search(r.Context(), req.Query)
+	if err != nil {
+		WriteError(w, NewUpstreamError(err))
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseSearch(results...))
+}
+
+func (h *Handler) preview(w http.ResponseWriter, r *http.Request) {
+	req := new(RequestPreview)
+	if err := req.bind(r); err != nil {
+		WriteError(w, newInvalidRequestError(err))
+
+		return
+	}
+
+	result, err := h.previews.Preview(r.Context(), req.URL)
+	if err != nil {
+		WriteError(w, NewUpstreamError(err))
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	_ = json.NewEncoder(w).Encode(NewResponseTimelines(result))
+}
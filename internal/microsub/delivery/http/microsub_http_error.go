@@ -0,0 +1,155 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/sub/internal/auth"
+	"source.toby3d.me/toby3d/sub/internal/block"
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+	"source.toby3d.me/toby3d/sub/internal/mute"
+	"source.toby3d.me/toby3d/sub/internal/search"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	"source.toby3d.me/toby3d/sub/internal/webmention"
+)
+
+// Error is a failed request response with HTTP status code and Microsub
+// error code.
+type Error struct {
+	err    error
+	Code   string
+	Status int
+}
+
+const (
+	ErrorCodeForbidden              string = "forbidden"
+	ErrorCodeInsufficientScope      string = "insufficient_scope"
+	ErrorCodeInvalidRequest         string = "invalid_request"
+	ErrorCodeInvalidToken           string = "invalid_token"
+	ErrorCodeNotFound               string = "not_found"
+	ErrorCodeServerError            string = "server_error"
+	ErrorCodeTemporarilyUnavailable string = "temporarily_unavailable"
+	ErrorCodeUnauthorized           string = "unauthorized"
+)
+
+// errorsStatuses maps known errors of use cases to response codes. Errors
+// which are not listed here are treated as internal server errors.
+var errorsStatuses = []struct {
+	target error
+	code   string
+	status int
+}{
+	{target: auth.ErrNoToken, code: ErrorCodeUnauthorized, status: http.StatusUnauthorized},
+	{target: auth.ErrToken, code: ErrorCodeInvalidToken, status: http.StatusUnauthorized},
+	{target: ErrBody, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: domain.ErrActionSyntax, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: domain.ErrMethodSyntax, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: domain.ErrUnreadModeSyntax, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: timeline.ErrCursor, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: search.ErrQuery, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: feed.ErrUnsupported, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: httpclient.ErrPrivate, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: channel.ErrNotifications, code: ErrorCodeForbidden, status: http.StatusForbidden},
+	{target: channel.ErrNotificationsUnread, code: ErrorCodeForbidden, status: http.StatusForbidden},
+	{target: channel.ErrNotExist, code: ErrorCodeNotFound, status: http.StatusNotFound},
+	{target: follow.ErrNotExist, code: ErrorCodeNotFound, status: http.StatusNotFound},
+	{target: mute.ErrNotExist, code: ErrorCodeNotFound, status: http.StatusNotFound},
+	{target: block.ErrNotExist, code: ErrorCodeNotFound, status: http.StatusNotFound},
+	{target: timeline.ErrNotExist, code: ErrorCodeNotFound, status: http.StatusNotFound},
+	{target: channel.ErrExist, code: ErrorCodeInvalidRequest, status: http.StatusConflict},
+	{target: follow.ErrExist, code: ErrorCodeInvalidRequest, status: http.StatusConflict},
+	{target: timeline.ErrExist, code: ErrorCodeInvalidRequest, status: http.StatusConflict},
+	{target: webmention.ErrRequest, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: webmention.ErrTarget, code: ErrorCodeInvalidRequest, status: http.StatusBadRequest},
+	{target: webmention.ErrQueue, code: ErrorCodeTemporarilyUnavailable, status: http.StatusServiceUnavailable},
+}
+
+// NewError wraps err with the status code of the first known error in its
+// chain, or keeps the status of already wrapped error.
+func NewError(err error) *Error {
+	out := new(Error)
+	if errors.As(err, &out) {
+		return out
+	}
+
+	for _, known := range errorsStatuses {
+		if errors.Is(err, known.target) {
+			return &Error{err: err, Code: known.code, Status: known.status}
+		}
+	}
+
+	return &Error{err: err, Code: ErrorCodeServerError, Status: http.StatusInternalServerError}
+}
+
+// NewMethodError rejects request with unsupported method.
+func NewMethodError(method string) *Error {
+	return &Error{
+		err:    fmt.Errorf("%s method is not allowed", method),
+		Code:   ErrorCodeInvalidRequest,
+		Status: http.StatusMethodNotAllowed,
+	}
+}
+
+// newInvalidRequestError marks err as a malformed request.
+func newInvalidRequestError(err error) *Error {
+	return &Error{err: err, Code: ErrorCodeInvalidRequest, Status: http.StatusBadRequest}
+}
+
+// NewUpstreamError marks err as a failure of remote server which is not known
+// as a client mistake.
+func NewUpstreamError(err error) *Error {
+	out := NewError(err)
+	if out.Status == http.StatusInternalServerError {
+		out.Status = http.StatusBadGateway
+	}
+
+	return out
+}
+
+func (e Error) Error() string {
+	if e.err == nil {
+		return e.Code
+	}
+
+	return e.err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.err
+}
+
+// WriteError responds with JSON body of err mapped by NewError. Details of
+// internal server errors are not exposed to clients.
+func WriteError(w http.ResponseWriter, err error) {
+	e := NewError(err)
+
+	description := e.Error()
+	if e.Status == http.StatusInternalServerError {
+		description = "internal server error"
+	}
+
+	// keep challenge already set by caller, it may contain more details
+	if w.Header().Get("WWW-Authenticate") == "" {
+		switch e.Code {
+		case ErrorCodeUnauthorized:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		case ErrorCodeInsufficientScope:
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="%s"`, e.Code))
+		}
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	w.WriteHeader(e.Status)
+	_ = json.NewEncoder(w).Encode(ResponseError{
+		Error:            e.Code,
+		ErrorDescription: description,
+	})
+}
@@ -0,0 +1,142 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/auth"
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+	channelucase "source.toby3d.me/toby3d/sub/internal/channel/usecase"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	eventmemory "source.toby3d.me/toby3d/sub/internal/event/memory"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	delivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+	"source.toby3d.me/toby3d/sub/internal/webmention"
+)
+
+func TestNewError(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		err    error
+		code   string
+		status int
+	}{
+		{err: fmt.Errorf("cannot find: %w", channel.ErrNotExist), code: "not_found", status: http.StatusNotFound},
+		{err: follow.ErrExist, code: "invalid_request", status: http.StatusConflict},
+		{err: fmt.Errorf("%w: expired", auth.ErrToken), code: "invalid_token", status: http.StatusUnauthorized},
+		{err: channel.ErrNotifications, code: "forbidden", status: http.StatusForbidden},
+		{err: channel.ErrNotificationsUnread, code: "forbidden", status: http.StatusForbidden},
+		{err: domain.ErrActionSyntax, code: "invalid_request", status: http.StatusBadRequest},
+		{err: domain.ErrMethodSyntax, code: "invalid_request", status: http.StatusBadRequest},
+		{err: webmention.ErrQueue, code: "temporarily_unavailable", status: http.StatusServiceUnavailable},
+		{err: errors.New("database is down"), code: "server_error", status: http.StatusInternalServerError},
+	} {
+		actual := delivery.NewError(tc.err)
+		if actual.Code != tc.code || actual.Status != tc.status {
+			t.Errorf("%v: want %s %d, got %s %d", tc.err, tc.code, tc.status, actual.Code, actual.Status)
+		}
+
+		if !errors.Is(actual, tc.err) {
+			t.Errorf("%v: want wrapped error, got %v", tc.err, actual)
+		}
+	}
+}
+
+func TestHandler_ServeHTTP_Error(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channelmemoryrepo.NewMemoryChannelRepository(),
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+	})
+
+	for name, tc := range map[string]struct {
+		form   url.Values
+		code   string
+		status int
+	}{
+		"action": {
+			form:   url.Values{"action": []string{"dance"}},
+			code:   "invalid_request",
+			status: http.StatusBadRequest,
+		},
+		"method": {
+			form:   url.Values{"action": []string{"channels"}, "method": []string{"dance"}, "channel": []string{"a"}},
+			code:   "invalid_request",
+			status: http.StatusBadRequest,
+		},
+		"not exist": {
+			form:   url.Values{"action": []string{"channels"}, "channel": []string{"unknown"}, "name": []string{"A"}},
+			code:   "not_found",
+			status: http.StatusNotFound,
+		},
+		"notifications": {
+			form: url.Values{"action": []string{"channels"}, "method": []string{"delete"},
+				"channel": []string{common.ChannelNotifications}},
+			code:   "forbidden",
+			status: http.StatusForbidden,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/",
+				strings.NewReader(tc.form.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+			req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.status {
+				t.Errorf("want %d, got %d", tc.status, resp.StatusCode)
+			}
+
+			actual := new(delivery.ResponseError)
+			if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+				t.Fatal(err)
+			}
+
+			if actual.Error != tc.code || actual.ErrorDescription == "" {
+				t.Errorf("want '%s' error with description, got %+v", tc.code, actual)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	delivery.WriteError(w, errors.New("cannot connect to 10.0.0.1:5432"))
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("want %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	actual := new(delivery.ResponseError)
+	if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.Error != "server_error" || strings.Contains(actual.ErrorDescription, "10.0.0.1") {
+		t.Errorf("want 'server_error' error without details, got %+v", actual)
+	}
+}
@@ -1,9 +1,19 @@
 package http
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/sub/internal/common"
 	"source.toby3d.me/toby3d/sub/internal/domain"
 )
 
@@ -14,9 +24,17 @@ type (
 	}
 
 	RequestChannelsUpdate struct {
-		Action  domain.Action // channels
-		Channel string
-		Name    string
+		Action domain.Action // channels
+		Unread domain.UnreadMode
+
+		// Retention is a new own retention policy of channel, nil if it's
+		// not changed.
+		Retention *domain.Retention
+		Channel   string
+		Name      string
+
+		// ResetRetention returns channel to the default retention policy.
+		ResetRetention bool
 	}
 
 	RequestChannelsOrder struct {
@@ -32,25 +50,113 @@ type (
 	}
 
 	ResponseChannel struct {
-		UID  string `json:"uid"`
-		Name string `json:"name"`
+		Retention *ResponseRetention `json:"retention,omitempty"`
+		UID       string             `json:"uid"`
+		Name      string             `json:"name"`
+	}
+
+	// ResponseRetention is an own retention policy of channel. Zero limits
+	// are omitted.
+	ResponseRetention struct {
+		MaxAge     string `json:"max_age,omitempty"`
+		MaxCount   int    `json:"max_count,omitempty"`
+		KeepUnread bool   `json:"keep_unread"`
 	}
 
 	RequestTimelines struct {
-		After   any
-		Before  any
-		Action  domain.Action
+		Action  domain.Action // timeline
+		Channel string
+		After   string
+		Before  string
+	}
+
+	RequestTimeline struct {
+		Action        domain.Action // timeline
+		Method        domain.Method // mark_read, mark_unread, remove
+		Channel       string
+		LastReadEntry string
+		Entries       []string
+	}
+
+	RequestFollows struct {
+		Action  domain.Action // follow
 		Channel string
 	}
 
+	RequestFollow struct {
+		URL     *url.URL
+		Action  domain.Action // follow
+		Channel string
+	}
+
+	RequestUnfollow struct {
+		URL     *url.URL
+		Action  domain.Action // unfollow
+		Channel string
+	}
+
+	RequestMutes struct {
+		Action  domain.Action // mute
+		Channel string
+	}
+
+	RequestMute struct {
+		URL     *url.URL
+		Action  domain.Action // mute
+		Channel string
+	}
+
+	RequestUnmute struct {
+		URL     *url.URL
+		Action  domain.Action // unmute
+		Channel string
+	}
+
+	RequestBlocks struct {
+		Action  domain.Action // block
+		Channel string
+	}
+
+	RequestBlock struct {
+		URL     *url.URL
+		Action  domain.Action // block
+		Channel string
+	}
+
+	RequestUnblock struct {
+		URL     *url.URL
+		Action  domain.Action // unblock
+		Channel string
+	}
+
+	RequestEvents struct {
+		Action  domain.Action // events
+		Channel string
+	}
+
+	RequestSearch struct {
+		Action domain.Action // search
+		Query  string
+	}
+
+	RequestPreview struct {
+		URL    *url.URL
+		Action domain.Action // preview
+	}
+
 	ResponseChannels struct {
 		Channels []ResponseChannelsChannel `json:"channels"`
 	}
 
 	ResponseChannelsChannel struct {
-		UID    string `json:"uid"`
-		Name   string `json:"name"`
-		Unread uint   `json:"unread"`
+		UID  string `json:"uid"`
+		Name string `json:"name"`
+
+		// Unread is a count of unread entries, a boolean indicator of
+		// their presence or absent, depending on channel unread mode.
+		Unread any `json:"unread,omitempty"`
+
+		Retention *ResponseRetention `json:"retention,omitempty"`
 	}
 
 	ResponseTimelines struct {
@@ -59,31 +165,32 @@ type (
 	}
 
 	ResponsePaging struct {
-		After  string `json:"after"`
-		Before string `json:"before"`
+		After  string `json:"after,omitempty"`
+		Before string `json:"before,omitempty"`
 	}
 
 	ResponseEntry struct {
-		Checkin     CardPlace       `json:"checkin"`
-		Author      CardPeople      `json:"author"`
-		Content     ResponseContent `json:"content"`
-		Type        string          `json:"type"`
-		Published   string          `json:"published"`
-		URL         string          `json:"url"`
-		UID         string          `json:"uid"`
-		Name        string          `json:"name"`
-		Summary     string          `json:"summary"`
-		ID          string          `json:"_id"`
-		Video       []string        `json:"video"`
-		Audio       []string        `json:"audio"`
-		LikeOf      []string        `json:"like-of"`
-		RepostOf    []string        `json:"repost-of"`
-		BookmarkOf  []string        `json:"bookmark-of"`
-		InReplyTo   []string        `json:"in-reply-of"`
-		Syndication []string        `json:"syndication"`
-		Photo       []string        `json:"photo"`
-		Category    []string        `json:"category"`
-		IsRead      bool            `json:"_is_read"`
+		Checkin     *CardPlace       `json:"checkin,omitempty"`
+		Author      *CardPeople      `json:"author,omitempty"`
+		Content     *ResponseContent `json:"content,omitempty"`
+		Type        string           `json:"type"`
+		Published   string           `json:"published,omitempty"`
+		Updated     string           `json:"updated,omitempty"`
+		URL         string           `json:"url,omitempty"`
+		UID         string           `json:"uid,omitempty"`
+		Name        string           `json:"name,omitempty"`
+		Summary     string           `json:"summary,omitempty"`
+		ID          string           `json:"_id"`
+		Video       []string         `json:"video,omitempty"`
+		Audio       []string         `json:"audio,omitempty"`
+		LikeOf      []string         `json:"like-of,omitempty"`
+		RepostOf    []string         `json:"repost-of,omitempty"`
+		BookmarkOf  []string         `json:"bookmark-of,omitempty"`
+		InReplyTo   []string         `json:"in-reply-to,omitempty"`
+		Syndication []string         `json:"syndication,omitempty"`
+		Photo       []string         `json:"photo,omitempty"`
+		Category    []string         `json:"category,omitempty"`
+		IsRead      bool             `json:"_is_read"`
 	}
 
 	ResponseAuthor struct {
@@ -94,47 +201,95 @@ type (
 	}
 
 	ResponseContent struct {
-		Text string `json:"text"`
-		HTML string `json:"html"`
+		Text string `json:"text,omitempty"`
+		HTML string `json:"html,omitempty"`
 	}
 
 	CardPlace struct {
 		Type          string `json:"type"`
-		Name          string `json:"name"`
-		URL           string `json:"url"`
-		Latitude      string `json:"latitude"`
-		Longitude     string `json:"longitude"`
-		StreetAddress string `json:"street-address"`
-		Locality      string `json:"locality"`
-		Region        string `json:"region"`
-		Country       string `json:"country"`
+		Name          string `json:"name,omitempty"`
+		URL           string `json:"url,omitempty"`
+		Latitude      string `json:"latitude,omitempty"`
+		Longitude     string `json:"longitude,omitempty"`
+		StreetAddress string `json:"street-address,omitempty"`
+		Locality      string `json:"locality,omitempty"`
+		Region        string `json:"region,omitempty"`
+		Country       string `json:"country,omitempty"`
 	}
 
 	CardPeople struct {
 		Type  string `json:"type"`
-		Name  string `json:"name"`
-		URL   string `json:"url"`
-		Photo string `json:"photo"`
+		Name  string `json:"name,omitempty"`
+		URL   string `json:"url,omitempty"`
+		Photo string `json:"photo,omitempty"`
+	}
+
+	ResponseFollows struct {
+		Items []ResponseFeed `json:"items"`
 	}
 
-	ResponseSource struct {
+	ResponseMutes struct {
+		Items []ResponseAuthor `json:"items"`
+	}
+
+	ResponseBlocks struct {
+		Items []ResponseAuthor `json:"items"`
+	}
+
+	ResponseEventNewItem struct {
+		Channel string        `json:"channel"`
+		Item    ResponseEntry `json:"item"`
+	}
+
+	ResponseEventChannel struct {
+		Channel ResponseChannel `json:"channel"`
+	}
+
+	ResponseEventChannelDeleted struct {
+		Channel string `json:"channel"`
+	}
+
+	ResponseEventMark struct {
+		Channel string   `json:"channel"`
+		Entries []string `json:"entries"`
+	}
+
+	ResponseSearch struct {
+		Results []ResponseFeed `json:"results"`
+	}
+
+	ResponseFeed struct {
+		Type  string `json:"type"`
 		URL   string `json:"url"`
-		Name  string `json:"name"`
-		Photo string `json:"photo"`
-		ID    string `json:"_id"`
+		Name  string `json:"name,omitempty"`
+		Photo string `json:"photo,omitempty"`
+	}
+
+	ResponseError struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description,omitempty"`
 	}
 )
 
-func NewResponseChannels(channels ...domain.Channel) *ResponseChannels {
+// NewResponseChannels returns channels list with the count of unread entries
+// in each of them taken from unread by channel UID.
+func NewResponseChannels(unread map[string]int, channels ...domain.Channel) *ResponseChannels {
 	out := &ResponseChannels{
 		Channels: make([]ResponseChannelsChannel, len(channels)),
 	}
 
 	for i := range channels {
 		out.Channels[i] = ResponseChannelsChannel{
-			UID:    channels[i].UID,
-			Name:   channels[i].Name,
-			Unread: 0,
+			UID:       channels[i].UID,
+			Name:      channels[i].Name,
+			Retention: NewResponseRetention(channels[i].Retention),
+		}
+
+		switch channels[i].Unread {
+		case domain.UnreadModeUnd, domain.UnreadModeCount:
+			out.Channels[i].Unread = unread[channels[i].UID]
+		case domain.UnreadModeIndicator:
+			out.Channels[i].Unread = unread[channels[i].UID] > 0
 		}
 	}
 
@@ -149,6 +304,198 @@ func NewResponseChannel(c *domain.Channel) *ResponseChannel {
 	}
 	out.UID = c.UID
 	out.Name = c.Name
+	out.Retention = NewResponseRetention(c.Retention)
+
+	return out
+}
+
+// NewResponseRetention returns own retention policy of channel, or nil for
+// channels with the default one.
+func NewResponseRetention(r *domain.Retention) *ResponseRetention {
+	if r == nil {
+		return nil
+	}
+
+	out := &ResponseRetention{
+		MaxCount:   r.MaxCount,
+		KeepUnread: r.KeepUnread,
+	}
+
+	if r.MaxAge > 0 {
+		out.MaxAge = r.MaxAge.String()
+	}
+
+	return out
+}
+
+func NewResponseTimelines(t *domain.Timeline) *ResponseTimelines {
+	out := &ResponseTimelines{
+		Items: make([]ResponseEntry, 0),
+	}
+
+	if t == nil {
+		return out
+	}
+
+	out.Paging.After = t.Paging.After
+	out.Paging.Before = t.Paging.Before
+
+	for i := range t.Items {
+		out.Items = append(out.Items, NewResponseEntry(t.Items[i]))
+	}
+
+	return out
+}
+
+func NewResponseEntry(e domain.Entry) ResponseEntry {
+	out := ResponseEntry{
+		Type:        "entry",
+		ID:          e.ID,
+		UID:         e.UID,
+		URL:         e.URL,
+		Name:        e.Name,
+		Summary:     e.Summary,
+		Photo:       e.Photo,
+		Video:       e.Video,
+		Audio:       e.Audio,
+		LikeOf:      e.LikeOf,
+		RepostOf:    e.RepostOf,
+		BookmarkOf:  e.BookmarkOf,
+		InReplyTo:   e.InReplyTo,
+		Syndication: e.Syndication,
+		Category:    e.Category,
+		IsRead:      e.IsRead,
+	}
+
+	if !e.Published.IsZero() {
+		out.Published = e.Published.Format(time.RFC3339)
+	}
+
+	if !e.Updated.IsZero() {
+		out.Updated = e.Updated.Format(time.RFC3339)
+	}
+
+	if e.Content != nil {
+		out.Content = &ResponseContent{
+			Text: e.Content.Text,
+			HTML: e.Content.HTML,
+		}
+	}
+
+	if e.Author != nil {
+		out.Author = &CardPeople{
+			Type:  "card",
+			Name:  e.Author.Name,
+			URL:   e.Author.URL,
+			Photo: e.Author.Photo,
+		}
+	}
+
+	if e.Checkin != nil {
+		out.Checkin = &CardPlace{
+			Type:          "card",
+			Name:          e.Checkin.Name,
+			URL:           e.Checkin.URL,
+			Latitude:      e.Checkin.Latitude,
+			Longitude:     e.Checkin.Longitude,
+			StreetAddress: e.Checkin.StreetAddress,
+			Locality:      e.Checkin.Locality,
+			Region:        e.Checkin.Region,
+			Country:       e.Checkin.Country,
+		}
+	}
+
+	return out
+}
+
+func NewResponseFollows(feeds ...domain.Feed) *ResponseFollows {
+	out := &ResponseFollows{
+		Items: make([]ResponseFeed, len(feeds)),
+	}
+
+	for i := range feeds {
+		out.Items[i] = NewResponseFeed(feeds[i])
+	}
+
+	return out
+}
+
+func NewResponseMutes(authors ...domain.Card) *ResponseMutes {
+	out := &ResponseMutes{
+		Items: make([]ResponseAuthor, len(authors)),
+	}
+
+	for i := range authors {
+		out.Items[i] = NewResponseAuthor(authors[i])
+	}
+
+	return out
+}
+
+func NewResponseBlocks(authors ...domain.Card) *ResponseBlocks {
+	out := &ResponseBlocks{
+		Items: make([]ResponseAuthor, len(authors)),
+	}
+
+	for i := range authors {
+		out.Items[i] = NewResponseAuthor(authors[i])
+	}
+
+	return out
+}
+
+func NewResponseAuthor(c domain.Card) ResponseAuthor {
+	return ResponseAuthor{
+		Type:  "card",
+		Name:  c.Name,
+		URL:   c.URL,
+		Photo: c.Photo,
+	}
+}
+
+// NewResponseEvent returns the data of streamed event depending on its type.
+func NewResponseEvent(e domain.Event) any {
+	switch e.Type {
+	case domain.EventTypeNewItem:
+		out := &ResponseEventNewItem{Channel: e.Channel.UID}
+		if e.Entry != nil {
+			out.Item = NewResponseEntry(*e.Entry)
+		}
+
+		return out
+	case domain.EventTypeChannelCreated, domain.EventTypeChannelChanged:
+		return &ResponseEventChannel{Channel: *NewResponseChannel(&e.Channel)}
+	case domain.EventTypeChannelDeleted:
+		return &ResponseEventChannelDeleted{Channel: e.Channel.UID}
+	case domain.EventTypeMarkRead, domain.EventTypeMarkUnread:
+		return &ResponseEventMark{Channel: e.Channel.UID, Entries: e.Entries}
+	default:
+		return struct{}{}
+	}
+}
+
+func NewResponseSearch(feeds ...domain.Feed) *ResponseSearch {
+	out := &ResponseSearch{
+		Results: make([]ResponseFeed, len(feeds)),
+	}
+
+	for i := range feeds {
+		out.Results[i] = NewResponseFeed(feeds[i])
+	}
+
+	return out
+}
+
+func NewResponseFeed(f domain.Feed) ResponseFeed {
+	out := ResponseFeed{
+		Type:  "feed",
+		Name:  f.Name,
+		Photo: f.Photo,
+	}
+
+	if f.URL != nil {
+		out.URL = f.URL.String()
+	}
 
 	return out
 }
@@ -184,13 +531,69 @@ func (r *RequestChannelsUpdate) bind(req *http.Request) error {
 		return fmt.Errorf("expect channel UID value, but it's not provided")
 	}
 
-	if r.Name = req.PostFormValue("name"); r.Name == "" {
+	if req.PostForm.Has("unread") {
+		if r.Unread, err = domain.ParseUnreadMode(req.PostFormValue("unread")); err != nil {
+			return fmt.Errorf("cannot decode channel update request: %w", err)
+		}
+	}
+
+	if err = r.bindRetention(req); err != nil {
+		return fmt.Errorf("cannot decode channel update request: %w", err)
+	}
+
+	if r.Name = req.PostFormValue("name"); r.Name == "" && r.Unread == domain.UnreadModeUnd &&
+		r.Retention == nil && !r.ResetRetention {
 		return fmt.Errorf("expect channel name value, but it's not provided")
 	}
 
 	return nil
 }
 
+// bindRetention decodes retention policy from 'retention_max_age',
+// 'retention_max_count' and 'retention_keep_unread' values, or reset of the
+// policy from 'retention=default' value. Policy values which are not provided
+// disable their limits.
+func (r *RequestChannelsUpdate) bindRetention(req *http.Request) error {
+	if req.PostForm.Has("retention") {
+		if v := req.PostFormValue("retention"); v != "default" {
+			return fmt.Errorf("expect 'default' retention value, got '%s'", v)
+		}
+
+		r.ResetRetention = true
+
+		return nil
+	}
+
+	if !req.PostForm.Has("retention_max_age") && !req.PostForm.Has("retention_max_count") &&
+		!req.PostForm.Has("retention_keep_unread") {
+		return nil
+	}
+
+	r.Retention = new(domain.Retention)
+
+	var err error
+
+	if v := req.PostFormValue("retention_max_age"); v != "" {
+		if r.Retention.MaxAge, err = time.ParseDuration(v); err != nil || r.Retention.MaxAge < 0 {
+			return fmt.Errorf("expect non-negative duration of retention_max_age, got '%s'", v)
+		}
+	}
+
+	if v := req.PostFormValue("retention_max_count"); v != "" {
+		if r.Retention.MaxCount, err = strconv.Atoi(v); err != nil || r.Retention.MaxCount < 0 {
+			return fmt.Errorf("expect non-negative number of retention_max_count, got '%s'", v)
+		}
+	}
+
+	if v := req.PostFormValue("retention_keep_unread"); v != "" {
+		if r.Retention.KeepUnread, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("expect boolean retention_keep_unread, got '%s'", v)
+		}
+	}
+
+	return nil
+}
+
 func (r *RequestChannelsOrder) bind(req *http.Request) error {
 	var err error
 	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
@@ -209,7 +612,11 @@ func (r *RequestChannelsOrder) bind(req *http.Request) error {
 		return fmt.Errorf("expect '%s' method, got '%s'", domain.MethodOrder, r.Method)
 	}
 
-	println(req.PostFormValue("channels[]"))
+	// single channel may be sent without brackets
+	r.Channel = append(append(make([]string, 0), req.PostForm["channels[]"]...), req.PostForm["channels"]...)
+	if len(r.Channel) == 0 {
+		return fmt.Errorf("expect channels value, but it's not provided")
+	}
 
 	return nil
 }
@@ -238,3 +645,389 @@ func (r *RequestChannelsDelete) bind(req *http.Request) error {
 
 	return nil
 }
+
+func (r *RequestTimelines) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.URL.Query().Get("action")); err != nil {
+		return fmt.Errorf("cannot decode timeline request: %w", err)
+	}
+
+	if r.Action != domain.ActionTimeline {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionTimeline, r.Action)
+	}
+
+	if r.Channel = req.URL.Query().Get("channel"); r.Channel == "" {
+		return fmt.Errorf("expect channel UID value, but it's not provided")
+	}
+
+	r.After = req.URL.Query().Get("after")
+	r.Before = req.URL.Query().Get("before")
+
+	return nil
+}
+
+func (r *RequestTimeline) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode timeline request: %w", err)
+	}
+
+	if r.Action != domain.ActionTimeline {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionTimeline, r.Action)
+	}
+
+	if r.Method, err = domain.ParseMethod(req.PostFormValue("method")); err != nil {
+		return fmt.Errorf("cannot decode timeline request: %w", err)
+	}
+
+	if r.Channel = req.PostFormValue("channel"); r.Channel == "" {
+		return fmt.Errorf("expect channel UID value, but it's not provided")
+	}
+
+	// single entry may be sent without brackets
+	r.Entries = append(append(make([]string, 0), req.PostForm["entry[]"]...), req.PostForm["entry"]...)
+	r.LastReadEntry = req.PostFormValue("last_read_entry")
+
+	switch r.Method {
+	default:
+		return fmt.Errorf("%w: %s", domain.ErrMethodSyntax, r.Method)
+	case domain.MethodMarkRead:
+		if len(r.Entries) == 0 && r.LastReadEntry == "" {
+			return fmt.Errorf("expect entry or last_read_entry value, but it's not provided")
+		}
+	case domain.MethodMarkUnread, domain.MethodRemove:
+		if len(r.Entries) == 0 {
+			return fmt.Errorf("expect entry value, but it's not provided")
+		}
+	}
+
+	return nil
+}
+
+func (r *RequestFollows) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.URL.Query().Get("action")); err != nil {
+		return fmt.Errorf("cannot decode follows request: %w", err)
+	}
+
+	if r.Action != domain.ActionFollow {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionFollow, r.Action)
+	}
+
+	if r.Channel = req.URL.Query().Get("channel"); r.Channel == "" {
+		return fmt.Errorf("expect channel UID value, but it's not provided")
+	}
+
+	return nil
+}
+
+func (r *RequestFollow) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode follow request: %w", err)
+	}
+
+	if r.Action != domain.ActionFollow {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionFollow, r.Action)
+	}
+
+	if r.Channel = req.PostFormValue("channel"); r.Channel == "" {
+		return fmt.Errorf("expect channel UID value, but it's not provided")
+	}
+
+	if r.URL, err = parseURL(req.PostFormValue("url")); err != nil {
+		return fmt.Errorf("cannot decode follow request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RequestUnfollow) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode unfollow request: %w", err)
+	}
+
+	if r.Action != domain.ActionUnfollow {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionUnfollow, r.Action)
+	}
+
+	if r.Channel = req.PostFormValue("channel"); r.Channel == "" {
+		return fmt.Errorf("expect channel UID value, but it's not provided")
+	}
+
+	if r.URL, err = parseURL(req.PostFormValue("url")); err != nil {
+		return fmt.Errorf("cannot decode unfollow request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RequestMutes) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.URL.Query().Get("action")); err != nil {
+		return fmt.Errorf("cannot decode mutes request: %w", err)
+	}
+
+	if r.Action != domain.ActionMute {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionMute, r.Action)
+	}
+
+	// users are muted globally if channel is not provided
+	if r.Channel = req.URL.Query().Get("channel"); r.Channel == "" {
+		r.Channel = common.ChannelGlobal
+	}
+
+	return nil
+}
+
+func (r *RequestMute) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode mute request: %w", err)
+	}
+
+	if r.Action != domain.ActionMute {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionMute, r.Action)
+	}
+
+	if r.Channel = req.PostFormValue("channel"); r.Channel == "" {
+		r.Channel = common.ChannelGlobal
+	}
+
+	if r.URL, err = parseURL(req.PostFormValue("url")); err != nil {
+		return fmt.Errorf("cannot decode mute request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RequestUnmute) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode unmute request: %w", err)
+	}
+
+	if r.Action != domain.ActionUnmute {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionUnmute, r.Action)
+	}
+
+	if r.Channel = req.PostFormValue("channel"); r.Channel == "" {
+		r.Channel = common.ChannelGlobal
+	}
+
+	if r.URL, err = parseURL(req.PostFormValue("url")); err != nil {
+		return fmt.Errorf("cannot decode unmute request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RequestBlocks) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.URL.Query().Get("action")); err != nil {
+		return fmt.Errorf("cannot decode blocks request: %w", err)
+	}
+
+	if r.Action != domain.ActionBlock {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionBlock, r.Action)
+	}
+
+	// users are blocked globally if channel is not provided
+	if r.Channel = req.URL.Query().Get("channel"); r.Channel == "" {
+		r.Channel = common.ChannelGlobal
+	}
+
+	return nil
+}
+
+func (r *RequestBlock) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode block request: %w", err)
+	}
+
+	if r.Action != domain.ActionBlock {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionBlock, r.Action)
+	}
+
+	if r.Channel = req.PostFormValue("channel"); r.Channel == "" {
+		r.Channel = common.ChannelGlobal
+	}
+
+	if r.URL, err = parseURL(req.PostFormValue("url")); err != nil {
+		return fmt.Errorf("cannot decode block request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RequestUnblock) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.PostFormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode unblock request: %w", err)
+	}
+
+	if r.Action != domain.ActionUnblock {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionUnblock, r.Action)
+	}
+
+	if r.Channel = req.PostFormValue("channel"); r.Channel == "" {
+		r.Channel = common.ChannelGlobal
+	}
+
+	if r.URL, err = parseURL(req.PostFormValue("url")); err != nil {
+		return fmt.Errorf("cannot decode unblock request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RequestEvents) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.URL.Query().Get("action")); err != nil {
+		return fmt.Errorf("cannot decode events request: %w", err)
+	}
+
+	if r.Action != domain.ActionEvents {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionEvents, r.Action)
+	}
+
+	// events of all channels are streamed if channel is not provided
+	r.Channel = req.URL.Query().Get("channel")
+
+	return nil
+}
+
+func (r *RequestSearch) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.FormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode search request: %w", err)
+	}
+
+	if r.Action != domain.ActionSearch {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionSearch, r.Action)
+	}
+
+	if r.Query = req.FormValue("query"); r.Query == "" {
+		return fmt.Errorf("expect query value, but it's not provided")
+	}
+
+	return nil
+}
+
+func (r *RequestPreview) bind(req *http.Request) error {
+	var err error
+	if r.Action, err = domain.ParseAction(req.FormValue("action")); err != nil {
+		return fmt.Errorf("cannot decode preview request: %w", err)
+	}
+
+	if r.Action != domain.ActionPreview {
+		return fmt.Errorf("expect '%s' action, got '%s'", domain.ActionPreview, r.Action)
+	}
+
+	if r.URL, err = parseURL(req.FormValue("url")); err != nil {
+		return fmt.Errorf("cannot decode preview request: %w", err)
+	}
+
+	return nil
+}
+
+// maxBodySize limits size of JSON encoded request body.
+const maxBodySize int64 = 1 << 20
+
+var ErrBody = errors.New("cannot decode request body")
+
+// parseBody decodes form or JSON encoded request body into req.PostForm, so
+// both encodings are bound and validated by the same rules. Arrays of JSON
+// object are stored under the keys with brackets, like 'channels[]'.
+func parseBody(req *http.Request) error {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(common.HeaderContentType))
+	if mediaType != common.MIMEApplicationJSON {
+		if err := req.ParseForm(); err != nil {
+			return fmt.Errorf("%w: %s", ErrBody, err)
+		}
+
+		return nil
+	}
+
+	src := make(map[string]any)
+
+	decoder := json.NewDecoder(io.LimitReader(req.Body, maxBodySize))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&src); err != nil {
+		return fmt.Errorf("%w: %s", ErrBody, err)
+	}
+
+	form := make(url.Values, len(src))
+
+	for key, value := range src {
+		switch v := value.(type) {
+		default:
+			val, err := formatValue(v)
+			if err != nil {
+				return fmt.Errorf("%w: '%s' %s", ErrBody, key, err)
+			}
+
+			form.Set(key, val)
+		case []any:
+			key = strings.TrimSuffix(key, "[]") + "[]"
+
+			for i := range v {
+				val, err := formatValue(v[i])
+				if err != nil {
+					return fmt.Errorf("%w: '%s' %s", ErrBody, key, err)
+				}
+
+				form.Add(key, val)
+			}
+		}
+	}
+
+	req.PostForm = form
+	req.Form = make(url.Values, len(form))
+
+	for key, values := range req.URL.Query() {
+		req.Form[key] = append(req.Form[key], values...)
+	}
+
+	// body values take precedence over query ones like in ParseForm
+	for key, values := range form {
+		req.Form[key] = append(append(make([]string, 0), values...), req.Form[key]...)
+	}
+
+	return nil
+}
+
+// formatValue returns string representation of JSON scalar value.
+func formatValue(v any) (string, error) {
+	switch val := v.(type) {
+	default:
+		return "", fmt.Errorf("expect string, number or boolean value, got %T", v)
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	}
+}
+
+// parseURL parses src as absolute HTTP(S) URL.
+func parseURL(src string) (*url.URL, error) {
+	if src == "" {
+		return nil, fmt.Errorf("expect url value, but it's not provided")
+	}
+
+	out, err := url.Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse url: %w", err)
+	}
+
+	if (out.Scheme != "http" && out.Scheme != "https") || out.Host == "" {
+		return nil, fmt.Errorf("expect absolute http or https url, got '%s'", src)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+// scopes maps HTTP method and action of request to the scope which access
+// token must grant to perform it. All methods of POST action share a single
+// scope: managing channels requires 'channels', marking entries as read or
+// removing them requires 'read'.
+var scopes = map[string]map[domain.Action]domain.Scope{
+	http.MethodGet: {
+		domain.ActionBlock:    domain.ScopeBlock,
+		domain.ActionChannels: domain.ScopeRead,
+		domain.ActionEvents:   domain.ScopeRead,
+		domain.ActionFollow:   domain.ScopeFollow,
+		domain.ActionMute:     domain.ScopeMute,
+		domain.ActionPreview:  domain.ScopeFollow,
+		domain.ActionSearch:   domain.ScopeFollow,
+		domain.ActionTimeline: domain.ScopeRead,
+	},
+	http.MethodPost: {
+		domain.ActionBlock:    domain.ScopeBlock,
+		domain.ActionChannels: domain.ScopeChannels,
+		domain.ActionFollow:   domain.ScopeFollow,
+		domain.ActionMute:     domain.ScopeMute,
+		domain.ActionPreview:  domain.ScopeFollow,
+		domain.ActionSearch:   domain.ScopeFollow,
+		domain.ActionTimeline: domain.ScopeRead,
+		domain.ActionUnblock:  domain.ScopeBlock,
+		domain.ActionUnfollow: domain.ScopeFollow,
+		domain.ActionUnmute:   domain.ScopeMute,
+	},
+}
+
+// RequiredScope returns the scope needed to perform action by provided HTTP
+// method, or ScopeUnd if such combination is not supported.
+func RequiredScope(method string, action domain.Action) domain.Scope {
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	if scope, ok := scopes[method][action]; ok {
+		return scope
+	}
+
+	return domain.ScopeUnd
+}
@@ -0,0 +1,70 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	delivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+)
+
+func TestRequiredScope(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		method string
+		action domain.Action
+		expect domain.Scope
+	}{
+		{method: "", action: domain.ActionTimeline, expect: domain.ScopeRead},
+		{method: http.MethodGet, action: domain.ActionChannels, expect: domain.ScopeRead},
+		{method: http.MethodGet, action: domain.ActionEvents, expect: domain.ScopeRead},
+		{method: http.MethodGet, action: domain.ActionFollow, expect: domain.ScopeFollow},
+		{method: http.MethodGet, action: domain.ActionSearch, expect: domain.ScopeFollow},
+		{method: http.MethodGet, action: domain.ActionUnfollow, expect: domain.ScopeUnd},
+		{method: http.MethodPost, action: domain.ActionChannels, expect: domain.ScopeChannels},
+		{method: http.MethodPost, action: domain.ActionTimeline, expect: domain.ScopeRead},
+		{method: http.MethodPost, action: domain.ActionPreview, expect: domain.ScopeFollow},
+		{method: http.MethodPost, action: domain.ActionUnfollow, expect: domain.ScopeFollow},
+		{method: http.MethodPost, action: domain.ActionMute, expect: domain.ScopeMute},
+		{method: http.MethodPost, action: domain.ActionUnmute, expect: domain.ScopeMute},
+		{method: http.MethodPost, action: domain.ActionBlock, expect: domain.ScopeBlock},
+		{method: http.MethodPost, action: domain.ActionUnblock, expect: domain.ScopeBlock},
+		{method: http.MethodPost, action: domain.ActionEvents, expect: domain.ScopeUnd},
+		{method: http.MethodDelete, action: domain.ActionChannels, expect: domain.ScopeUnd},
+	} {
+		if actual := delivery.RequiredScope(tc.method, tc.action); actual != tc.expect {
+			t.Errorf("%s %s: want %s scope, got %s", tc.method, tc.action, tc.expect, actual)
+		}
+	}
+}
+
+func TestHandler_ServeHTTP_InsufficientScope(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	user.Scopes = []domain.Scope{domain.ScopeFollow}
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/?action=channels", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+	w := httptest.NewRecorder()
+	delivery.NewHandler(delivery.NewHandlerOptions{}).ServeHTTP(w, req)
+
+	resp := w.Result()
+	if expect := http.StatusForbidden; resp.StatusCode != expect {
+		t.Errorf("want %d, got %d", expect, resp.StatusCode)
+	}
+
+	actual := new(delivery.ResponseError)
+	if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "insufficient_scope"; actual.Error != expect {
+		t.Errorf("want '%s' error, got '%s'", expect, actual.Error)
+	}
+}
@@ -1,6 +1,7 @@
 package http_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -13,14 +14,27 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	authormemoryrepo "source.toby3d.me/toby3d/sub/internal/author/repository/memory"
+	blockucase "source.toby3d.me/toby3d/sub/internal/block/usecase"
 	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
 	channelucase "source.toby3d.me/toby3d/sub/internal/channel/usecase"
 	"source.toby3d.me/toby3d/sub/internal/common"
 	"source.toby3d.me/toby3d/sub/internal/domain"
+	eventmemory "source.toby3d.me/toby3d/sub/internal/event/memory"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/feed/rss"
+	"source.toby3d.me/toby3d/sub/internal/fetcher"
+	followmemoryrepo "source.toby3d.me/toby3d/sub/internal/follow/repository/memory"
+	followucase "source.toby3d.me/toby3d/sub/internal/follow/usecase"
 	delivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+	muteucase "source.toby3d.me/toby3d/sub/internal/mute/usecase"
+	previewucase "source.toby3d.me/toby3d/sub/internal/preview/usecase"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+	timelineucase "source.toby3d.me/toby3d/sub/internal/timeline/usecase"
 )
 
 var update = flag.Bool("update", false, "update golden files")
@@ -39,8 +53,12 @@ func TestHandler_ServeHTTP_ChannelsCreate(t *testing.T) {
 	req = req.WithContext(context.WithValue(req.Context(), "user", user))
 
 	w := httptest.NewRecorder()
-	delivery.NewHandler(channelucase.NewChannelUseCase(channelmemoryrepo.NewMemoryChannelRepository())).
-		ServeHTTP(w, req)
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channelmemoryrepo.NewMemoryChannelRepository(),
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+	}).ServeHTTP(w, req)
 
 	resp := w.Result()
 	if expect := http.StatusOK; resp.StatusCode != expect {
@@ -78,9 +96,18 @@ func TestHandler_ServeHTTP_ChannelsFetch(t *testing.T) {
 		}
 	}
 
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+
 	w := httptest.NewRecorder()
-	delivery.NewHandler(channelucase.NewChannelUseCase(channels)).
-		ServeHTTP(w, req)
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channels,
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+		Timelines: timelines,
+	}).ServeHTTP(w, req)
 
 	resp := w.Result()
 	if expect := http.StatusOK; resp.StatusCode != expect {
@@ -131,8 +158,12 @@ func TestHandler_ServeHTTP_ChannelsDelete(t *testing.T) {
 	}
 
 	w := httptest.NewRecorder()
-	delivery.NewHandler(channelucase.NewChannelUseCase(channels)).
-		ServeHTTP(w, req)
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channels,
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+	}).ServeHTTP(w, req)
 
 	resp := w.Result()
 	if expect := http.StatusNoContent; resp.StatusCode != expect {
@@ -155,7 +186,7 @@ func TestHandler_ServeHTTP_ChannelsUpdate(t *testing.T) {
 	t.Parallel()
 
 	user := domain.TestUser(t)
-	channel := domain.TestChannel(t)
+	channel := &domain.Channel{UID: "9356ab8b8d566363f92026d06bf33aefba", Name: "IndieWeb"}
 
 	q := make(url.Values)
 	q.Set("action", domain.ActionChannels.String())
@@ -173,8 +204,12 @@ func TestHandler_ServeHTTP_ChannelsUpdate(t *testing.T) {
 	}
 
 	w := httptest.NewRecorder()
-	delivery.NewHandler(channelucase.NewChannelUseCase(channels)).
-		ServeHTTP(w, req)
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channels,
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+	}).ServeHTTP(w, req)
 
 	resp := w.Result()
 	if expect := http.StatusOK; resp.StatusCode != expect {
@@ -202,3 +237,639 @@ func TestHandler_ServeHTTP_ChannelsUpdate(t *testing.T) {
 		t.Error(cmp.Diff(actual, expected))
 	}
 }
+
+func TestHandler_ServeHTTP_Timeline(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/?action=timeline&channel=indieweb", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+
+	for _, e := range []domain.Entry{{
+		ID:        "1",
+		Channel:   "indieweb",
+		UID:       "https://aaronparecki.com/2023/03/01/1/",
+		URL:       "https://aaronparecki.com/2023/03/01/1/",
+		Published: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+		Author:    &domain.Card{Name: "Aaron Parecki", URL: "https://aaronparecki.com/"},
+		Content:   &domain.Content{Text: "Hello, World!"},
+	}, {
+		ID:        "2",
+		Channel:   "indieweb",
+		UID:       "https://tantek.com/2023/061/t1/",
+		URL:       "https://tantek.com/2023/061/t1/",
+		Name:      "IndieWeb Summit",
+		Published: time.Date(2023, time.March, 2, 12, 0, 0, 0, time.UTC),
+		Category:  []string{"indieweb"},
+	}} {
+		if err := entries.Create(context.Background(), *user, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	delivery.NewHandler(delivery.NewHandlerOptions{
+		Timelines: timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+			Entries: entries,
+		}),
+	}).ServeHTTP(w, req)
+
+	resp := w.Result()
+	if expect := http.StatusOK; resp.StatusCode != expect {
+		t.Errorf("want %d, got %d", expect, resp.StatusCode)
+	}
+
+	actual, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", t.Name()+".golden")
+	if *update {
+		if err = ioutil.WriteFile(golden, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(actual, expected) {
+		t.Error(cmp.Diff(actual, expected))
+	}
+}
+
+func TestHandler_ServeHTTP_Follow(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	if err := channels.Create(context.Background(), *user, *channel); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Follows: followucase.NewFollowUseCase(followmemoryrepo.NewMemoryFollowRepository(), channels),
+	})
+
+	q := make(url.Values)
+	q.Set("action", domain.ActionFollow.String())
+	q.Set("channel", channel.UID)
+	q.Set("url", "https://aaronparecki.com/")
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(q.Encode()))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationFormCharsetUTF8)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if resp := w.Result(); resp.StatusCode != http.StatusOK {
+		t.Fatalf("want %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/?action=follow&channel="+channel.UID, nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if expect := http.StatusOK; resp.StatusCode != expect {
+		t.Errorf("want %d, got %d", expect, resp.StatusCode)
+	}
+
+	actual := new(delivery.ResponseFollows)
+	if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &delivery.ResponseFollows{Items: []delivery.ResponseFeed{{
+		Type: "feed",
+		URL:  q.Get("url"),
+	}}}
+
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHandler_ServeHTTP_Preview(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(common.HeaderContentType, "application/rss+xml")
+		_, _ = io.WriteString(w, `<rss version="2.0"><channel><title>Example</title>
+<item><guid>https://example.com/1</guid><link>https://example.com/1</link><title>Hello</title></item>
+</channel></rss>`)
+	}))
+	t.Cleanup(srv.Close)
+
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Previews: previewucase.NewPreviewUseCase(fetcher.NewFetcher(fetcher.NewFetcherOptions{
+			Client: srv.Client(),
+			Parser: feed.NewParser(rss.Format{}),
+		})),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/?action=preview&url="+
+		url.QueryEscape(srv.URL+"/feed.xml"), nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", domain.TestUser(t)))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if expect := http.StatusOK; resp.StatusCode != expect {
+		t.Fatalf("want %d, got %d", expect, resp.StatusCode)
+	}
+
+	actual := new(delivery.ResponseTimelines)
+	if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actual.Items) != 1 || actual.Items[0].UID != "https://example.com/1" || actual.Items[0].Name != "Hello" {
+		t.Errorf("unexpected preview items: %+v", actual.Items)
+	}
+}
+
+func TestHandler_ServeHTTP_TimelineMarkRead(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	if err := channels.Create(context.Background(), *user, *channel); err != nil {
+		t.Fatal(err)
+	}
+
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+
+	entries, err := timelines.Create(context.Background(), *user, channel.UID, *domain.TestEntry(t),
+		*domain.TestEntry(t), *domain.TestEntry(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channels,
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+		Timelines: timelines,
+	})
+
+	q := make(url.Values)
+	q.Set("action", domain.ActionTimeline.String())
+	q.Set("method", domain.MethodMarkRead.String())
+	q.Set("channel", channel.UID)
+	q.Add("entry[]", entries[0].ID)
+	q.Add("entry[]", entries[1].ID)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(q.Encode()))
+	req.Header.Set(common.HeaderContentType, common.MIMEApplicationFormCharsetUTF8)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if resp := w.Result(); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("want %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/?action=channels", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	actual := new(delivery.ResponseChannels)
+	if err = json.NewDecoder(w.Result().Body).Decode(actual); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &delivery.ResponseChannels{Channels: []delivery.ResponseChannelsChannel{
+		{UID: common.ChannelNotifications, Name: "Notifications", Unread: float64(0)},
+		{UID: channel.UID, Name: channel.Name, Unread: float64(1)},
+	}}
+
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHandler_ServeHTTP_ChannelsUnread(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channels,
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+		Timelines: timelines,
+	})
+
+	for _, c := range []domain.Channel{
+		{UID: "count", Name: "Count"},
+		{UID: "indicator", Name: "Indicator"},
+		{UID: "off", Name: "Off"},
+	} {
+		if err := channels.Create(context.Background(), *user, c); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := timelines.Create(context.Background(), *user, c.UID, *domain.TestEntry(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		q := make(url.Values)
+		q.Set("action", domain.ActionChannels.String())
+		q.Set("channel", c.UID)
+		q.Set("unread", c.UID)
+
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(q.Encode()))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationFormCharsetUTF8)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if resp := w.Result(); resp.StatusCode != http.StatusOK {
+			t.Fatalf("want %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/?action=channels", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	actual, err := io.ReadAll(w.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"channels":[{"uid":"notifications","name":"Notifications","unread":0},` +
+		`{"uid":"count","name":"Count","unread":1},{"uid":"indicator","name":"Indicator","unread":true},` +
+		`{"uid":"off","name":"Off"}]}` + "\n"
+	if string(actual) != expect {
+		t.Errorf("want %s, got %s", expect, actual)
+	}
+}
+
+func TestHandler_ServeHTTP_ChannelsRetention(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: channels,
+			Events:   eventmemory.NewMemoryEventBus(),
+		}),
+	})
+
+	if err := channels.Create(context.Background(), *user, domain.Channel{UID: "news", Name: "News"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		channel      string
+		values       map[string]string
+		expect       string
+		expectStatus int
+	}{
+		"policy": {
+			channel:      "news",
+			values:       map[string]string{"retention_max_age": "72h", "retention_keep_unread": "true"},
+			expectStatus: http.StatusOK,
+			expect:       `{"retention":{"max_age":"72h0m0s","keep_unread":true},"uid":"news","name":"News"}`,
+		},
+		"default": {
+			channel:      "news",
+			values:       map[string]string{"retention": "default"},
+			expectStatus: http.StatusOK,
+			expect:       `{"uid":"news","name":"News"}`,
+		},
+		"invalid": {
+			channel:      "news",
+			values:       map[string]string{"retention_max_count": "-1"},
+			expectStatus: http.StatusBadRequest,
+		},
+		"notifications": {
+			channel:      common.ChannelNotifications,
+			values:       map[string]string{"retention_max_count": "10"},
+			expectStatus: http.StatusOK,
+			expect: `{"retention":{"max_count":10,"keep_unread":false},"uid":"notifications",` +
+				`"name":"Notifications"}`,
+		},
+	} {
+		q := make(url.Values)
+		q.Set("action", domain.ActionChannels.String())
+		q.Set("channel", tc.channel)
+
+		for k, v := range tc.values {
+			q.Set(k, v)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(q.Encode()))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationFormCharsetUTF8)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != tc.expectStatus {
+			t.Errorf("%s: want %d, got %d", name, tc.expectStatus, resp.StatusCode)
+
+			continue
+		}
+
+		if tc.expect == "" {
+			continue
+		}
+
+		actual, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(actual) != tc.expect+"\n" {
+			t.Errorf("%s: want %s, got %s", name, tc.expect, actual)
+		}
+	}
+}
+
+func TestHandler_ServeHTTP_Events(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	events := eventmemory.NewMemoryEventBus()
+	channels := channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+		Channels: channelmemoryrepo.NewMemoryChannelRepository(),
+		Events:   events,
+	})
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{
+		Channels:  channels,
+		Events:    events,
+		Heartbeat: 10 * time.Millisecond,
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "user", user)))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/?action=events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if expect := common.MIMETextEventStream; resp.Header.Get(common.HeaderContentType) != expect {
+		t.Errorf("want %s content type, got %s", expect, resp.Header.Get(common.HeaderContentType))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	// wait for subscription before producing events
+	if line, err := reader.ReadString('\n'); err != nil || line != ": connected\n" {
+		t.Fatalf("want connected comment, got '%s': %v", line, err)
+	}
+
+	channel, err := channels.Create(context.Background(), *user, "Testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	heartbeat := false
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line == ": heartbeat\n" {
+			heartbeat = true
+
+			continue
+		}
+
+		if line != "event: "+domain.EventTypeChannelCreated.String()+"\n" {
+			continue
+		}
+
+		data, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := new(delivery.ResponseEventChannel)
+		if err = json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), actual); err != nil {
+			t.Fatal(err)
+		}
+
+		if actual.Channel.UID != channel.UID {
+			t.Errorf("want %s channel, got %s", channel.UID, actual.Channel.UID)
+		}
+
+		break
+	}
+
+	for !heartbeat {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		heartbeat = line == ": heartbeat\n"
+	}
+}
+
+func TestHandler_ServeHTTP_JSON(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channels := channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+		Channels: channelmemoryrepo.NewMemoryChannelRepository(),
+		Events:   eventmemory.NewMemoryEventBus(),
+	})
+	handler := delivery.NewHandler(delivery.NewHandlerOptions{Channels: channels})
+
+	uids := make([]string, 0, 2)
+
+	for _, name := range []string{"IndieWeb", "W3C"} {
+		c, err := channels.Create(context.Background(), *user, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		uids = append(uids, c.UID)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		body   string
+		expect int
+	}{{
+		name:   "order",
+		body:   `{"action":"channels","method":"order","channels":["` + uids[1] + `","` + uids[0] + `"]}`,
+		expect: http.StatusNoContent,
+	}, {
+		name:   "malformed",
+		body:   `{"action":"channels","channels":`,
+		expect: http.StatusBadRequest,
+	}, {
+		name:   "object",
+		body:   `{"action":"channels","name":{"value":"Nested"}}`,
+		expect: http.StatusBadRequest,
+	}} {
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(tc.body))
+		req.Header.Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+		req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if actual := w.Result().StatusCode; actual != tc.expect {
+			t.Errorf("%s: want %d, got %d", tc.name, tc.expect, actual)
+		}
+	}
+
+	result, err := channels.Fetch(context.Background(), *user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0, len(result))
+	for i := range result {
+		if result[i].UID != common.ChannelNotifications {
+			actual = append(actual, result[i].UID)
+		}
+	}
+
+	if diff := cmp.Diff([]string{uids[1], uids[0]}, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHandler_ServeHTTP_MuteBlock(t *testing.T) {
+	t.Parallel()
+
+	for _, actions := range [][2]domain.Action{
+		{domain.ActionMute, domain.ActionUnmute},
+		{domain.ActionBlock, domain.ActionUnblock},
+	} {
+		add, remove := actions[0], actions[1]
+
+		t.Run(add.String(), func(t *testing.T) {
+			t.Parallel()
+
+			user := domain.TestUser(t)
+			channel := domain.TestChannel(t)
+			channels := channelmemoryrepo.NewMemoryChannelRepository()
+			entries := timelinememoryrepo.NewMemoryTimelineRepository()
+
+			if err := channels.Create(context.Background(), *user, *channel); err != nil {
+				t.Fatal(err)
+			}
+
+			handler := delivery.NewHandler(delivery.NewHandlerOptions{
+				Mutes: muteucase.NewMuteUseCase(authormemoryrepo.NewMemoryAuthorRepository(), channels,
+					entries),
+				Blocks: blockucase.NewBlockUseCase(authormemoryrepo.NewMemoryAuthorRepository(), channels,
+					entries),
+			})
+
+			do := func(method string, action domain.Action) *http.Response {
+				q := make(url.Values)
+				q.Set("action", action.String())
+				q.Set("channel", channel.UID)
+
+				if method == http.MethodGet {
+					req := httptest.NewRequest(method, "https://example.com/?"+q.Encode(), nil)
+					req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, req)
+
+					return w.Result()
+				}
+
+				q.Set("url", "https://alice.example.com/")
+
+				req := httptest.NewRequest(method, "https://example.com/", strings.NewReader(q.Encode()))
+				req.Header.Set(common.HeaderContentType, common.MIMEApplicationFormCharsetUTF8)
+				req = req.WithContext(context.WithValue(req.Context(), "user", user))
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+
+				return w.Result()
+			}
+
+			list := func() []delivery.ResponseAuthor {
+				resp := do(http.MethodGet, add)
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("want %d, got %d", http.StatusOK, resp.StatusCode)
+				}
+
+				out := new(delivery.ResponseMutes)
+				if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+					t.Fatal(err)
+				}
+
+				return out.Items
+			}
+
+			if resp := do(http.MethodPost, add); resp.StatusCode != http.StatusOK {
+				t.Fatalf("want %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+
+			if diff := cmp.Diff([]delivery.ResponseAuthor{{
+				Type: "card",
+				URL:  "https://alice.example.com/",
+			}}, list()); diff != "" {
+				t.Error(diff)
+			}
+
+			if resp := do(http.MethodPost, remove); resp.StatusCode != http.StatusNoContent {
+				t.Errorf("want %d, got %d", http.StatusNoContent, resp.StatusCode)
+			}
+
+			if actual := list(); len(actual) != 0 {
+				t.Errorf("want empty list, got %+v", actual)
+			}
+
+			if resp := do(http.MethodPost, remove); resp.StatusCode != http.StatusNotFound {
+				t.Errorf("want %d for unknown user, got %d", http.StatusNotFound, resp.StatusCode)
+			}
+		})
+	}
+}
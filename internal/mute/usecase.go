@@ -0,0 +1,22 @@
+package mute
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type UseCase interface {
+	// Fetch returns users muted in channel. Use common.ChannelGlobal for
+	// users muted everywhere.
+	Fetch(ctx context.Context, u domain.User, channel string) ([]domain.Card, error)
+
+	// Mute hides new entries of author in channel. Already muted author
+	// is returned as is.
+	Mute(ctx context.Context, u domain.User, channel string, author *url.URL) (*domain.Card, error)
+	Unmute(ctx context.Context, u domain.User, channel string, author *url.URL) error
+}
+
+var ErrNotExist = errors.New("user is not muted")
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/mute"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+)
+
+type muteUseCase struct {
+	mutes    author.Repository
+	channels channel.Repository
+	entries  timeline.Repository
+}
+
+func NewMuteUseCase(mutes author.Repository, channels channel.Repository, entries timeline.Repository) mute.UseCase {
+	return &muteUseCase{
+		mutes:    mutes,
+		channels: channels,
+		entries:  entries,
+	}
+}
+
+func (ucase *muteUseCase) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Card, error) {
+	authors, err := ucase.mutes.Fetch(ctx, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch muted users: %w", err)
+	}
+
+	return authors, nil
+}
+
+func (ucase *muteUseCase) Mute(ctx context.Context, u domain.User, cid string, src *url.URL) (*domain.Card, error) {
+	if cid != common.ChannelGlobal && cid != common.ChannelNotifications {
+		if _, err := ucase.channels.Get(ctx, u, cid); err != nil {
+			return nil, fmt.Errorf("cannot find channel for mute: %w", err)
+		}
+	}
+
+	if err := ucase.mutes.Create(ctx, u, cid, ucase.author(ctx, u, cid, src)); err != nil &&
+		!errors.Is(err, author.ErrExist) {
+		return nil, fmt.Errorf("cannot mute user: %w", err)
+	}
+
+	out, err := ucase.mutes.Get(ctx, u, cid, src)
+	if err != nil {
+		return nil, fmt.Errorf("cannot return muted user: %w", err)
+	}
+
+	return out, nil
+}
+
+func (ucase *muteUseCase) Unmute(ctx context.Context, u domain.User, cid string, src *url.URL) error {
+	if _, err := ucase.mutes.Get(ctx, u, cid, src); err != nil {
+		if errors.Is(err, author.ErrNotExist) {
+			err = mute.ErrNotExist
+		}
+
+		return fmt.Errorf("cannot find unmuting user: %w", err)
+	}
+
+	if err := ucase.mutes.Delete(ctx, u, cid, src); err != nil {
+		return fmt.Errorf("cannot unmute user: %w", err)
+	}
+
+	return nil
+}
+
+// author returns card of muted user, filled by the author of any known entry
+// with the same URL, so clients can show who is muted.
+func (ucase *muteUseCase) author(ctx context.Context, u domain.User, cid string, src *url.URL) domain.Card {
+	out := domain.Card{Type: "card", URL: src.String()}
+
+	cids := []string{cid}
+
+	if cid == common.ChannelGlobal {
+		cids = []string{common.ChannelNotifications}
+
+		// missing channels are not a reason to not mute
+		channels, _ := ucase.channels.Fetch(ctx, u)
+		for i := range channels {
+			cids = append(cids, channels[i].UID)
+		}
+	}
+
+	for _, cid := range cids {
+		entries, err := ucase.entries.Fetch(ctx, u, cid)
+		if err != nil {
+			continue
+		}
+
+		for i := range entries {
+			if entries[i].Author == nil || entries[i].Author.URL != out.URL {
+				continue
+			}
+
+			out.Name, out.Photo = entries[i].Author.Name, entries[i].Author.Photo
+
+			return out
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,95 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	authormemoryrepo "source.toby3d.me/toby3d/sub/internal/author/repository/memory"
+	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/mute"
+	ucase "source.toby3d.me/toby3d/sub/internal/mute/usecase"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+)
+
+func TestMuteUseCase_Mute(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	entry := domain.TestEntry(t)
+	entry.Channel = channel.UID
+
+	channels := channelmemoryrepo.NewMemoryChannelRepository()
+	if err := channels.Create(context.Background(), *user, *channel); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	if err := entries.Create(context.Background(), *user, *entry); err != nil {
+		t.Fatal(err)
+	}
+
+	author, _ := url.Parse(entry.Author.URL)
+	mutes := ucase.NewMuteUseCase(authormemoryrepo.NewMemoryAuthorRepository(), channels, entries)
+
+	for i := 0; i < 2; i++ {
+		if _, err := mutes.Mute(context.Background(), *user, channel.UID, author); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actual, err := mutes.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []domain.Card{{
+		Type:  "card",
+		Name:  entry.Author.Name,
+		URL:   entry.Author.URL,
+		Photo: entry.Author.Photo,
+	}}
+
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err = mutes.Mute(context.Background(), *user, "unknown", author); err == nil {
+		t.Error("expect error for unknown channel, got nil")
+	}
+
+	if _, err = mutes.Mute(context.Background(), *user, common.ChannelGlobal, author); err != nil {
+		t.Errorf("expect muting globally without channel, got %v", err)
+	}
+}
+
+func TestMuteUseCase_Unmute(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	author := domain.Card{Type: "card", URL: "https://example.com/"}
+	src, _ := url.Parse(author.URL)
+	mutes := authormemoryrepo.NewMemoryAuthorRepository()
+
+	if err := mutes.Create(context.Background(), *user, common.ChannelGlobal, author); err != nil {
+		t.Fatal(err)
+	}
+
+	muteUseCase := ucase.NewMuteUseCase(mutes, channelmemoryrepo.NewMemoryChannelRepository(),
+		timelinememoryrepo.NewMemoryTimelineRepository())
+
+	if err := muteUseCase.Unmute(context.Background(), *user, common.ChannelGlobal, src); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := muteUseCase.Unmute(context.Background(), *user, common.ChannelGlobal, src); !errors.Is(err,
+		mute.ErrNotExist) {
+		t.Errorf("expect %v, got %v", mute.ErrNotExist, err)
+	}
+}
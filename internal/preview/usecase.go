@@ -0,0 +1,21 @@
+package preview
+
+import (
+	"context"
+	"net/url"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	UseCase interface {
+		// Preview fetches and parses source by URL without following it
+		// or storing any of its entries.
+		Preview(ctx context.Context, u *url.URL) (*domain.Timeline, error)
+	}
+
+	// Fetcher downloads and parses a single source.
+	Fetcher interface {
+		Fetch(ctx context.Context, u *url.URL) (*domain.Feed, []domain.Entry, error)
+	}
+)
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/exp/slices"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/preview"
+)
+
+type previewUseCase struct {
+	fetcher preview.Fetcher
+}
+
+// limit is a maximum number of entries returned in preview.
+const limit int = 20
+
+func NewPreviewUseCase(fetcher preview.Fetcher) preview.UseCase {
+	return &previewUseCase{
+		fetcher: fetcher,
+	}
+}
+
+func (ucase *previewUseCase) Preview(ctx context.Context, u *url.URL) (*domain.Timeline, error) {
+	_, entries, err := ucase.fetcher.Fetch(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("cannot preview %s: %w", u, err)
+	}
+
+	for i := range entries {
+		entries[i].Source = u.String()
+	}
+
+	// newest first, as in timelines
+	slices.SortStableFunc(entries, func(a, b domain.Entry) bool {
+		return a.Published.After(b.Published)
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return &domain.Timeline{Items: entries}, nil
+}
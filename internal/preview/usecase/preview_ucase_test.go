@@ -0,0 +1,70 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	ucase "source.toby3d.me/toby3d/sub/internal/preview/usecase"
+)
+
+type stubFetcher struct {
+	entries []domain.Entry
+	err     error
+}
+
+func (f stubFetcher) Fetch(_ context.Context, u *url.URL) (*domain.Feed, []domain.Entry, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+
+	return &domain.Feed{URL: u}, f.entries, nil
+}
+
+func TestPreviewUseCase_Preview(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	entries := make([]domain.Entry, 25)
+
+	for i := range entries {
+		entries[i] = *domain.TestEntry(t)
+		entries[i].Published = now.Add(time.Duration(i) * time.Minute)
+	}
+
+	u, _ := url.Parse("https://example.com/feed.xml")
+
+	result, err := ucase.NewPreviewUseCase(stubFetcher{entries: entries}).Preview(context.Background(), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Items) != 20 {
+		t.Fatalf("want %d items, got %d", 20, len(result.Items))
+	}
+
+	if !result.Items[0].Published.Equal(now.Add(24 * time.Minute)) {
+		t.Errorf("want newest entry first, got %s", result.Items[0].Published)
+	}
+
+	for i := range result.Items {
+		if result.Items[i].Source != u.String() {
+			t.Errorf("want source %s, got %s", u, result.Items[i].Source)
+		}
+	}
+}
+
+func TestPreviewUseCase_Preview_Error(t *testing.T) {
+	t.Parallel()
+
+	expect := errors.New("boom")
+	u, _ := url.Parse("https://example.com/feed.xml")
+
+	if _, err := ucase.NewPreviewUseCase(stubFetcher{err: expect}).Preview(context.Background(), u); !errors.Is(err,
+		expect) {
+		t.Errorf("want %v, got %v", expect, err)
+	}
+}
@@ -0,0 +1,16 @@
+package search
+
+import (
+	"context"
+	"errors"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type UseCase interface {
+	// Search discovers feeds which can be followed by the URL or bare
+	// domain provided in query.
+	Search(ctx context.Context, query string) ([]domain.Feed, error)
+}
+
+var ErrQuery = errors.New("query must be a URL or domain name")
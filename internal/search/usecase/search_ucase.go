@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+	"source.toby3d.me/toby3d/sub/internal/search"
+)
+
+type (
+	searchUseCase struct {
+		client *http.Client
+		parser *feed.Parser
+	}
+
+	// document is a downloaded page or feed.
+	document struct {
+		url         *url.URL
+		contentType string
+		body        []byte
+	}
+
+	// candidate is a feed linked from HTML page.
+	candidate struct {
+		url   *url.URL
+		title string
+	}
+)
+
+const (
+	// maxBodySize limits size of downloaded documents.
+	maxBodySize int64 = 8 << 20
+
+	// maxCandidates limits number of discovered feeds which are fetched for
+	// their metadata.
+	maxCandidates int = 10
+)
+
+// feedTypes contains MIME types of rel=alternate links which point to feeds.
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+	"application/rdf+xml":   true,
+}
+
+// NewSearchUseCase creates search use case which fetches queried pages by
+// client. Client which refuses private networks is used if nil, because query
+// is an arbitrary user input.
+func NewSearchUseCase(client *http.Client, parser *feed.Parser) search.UseCase {
+	if client == nil {
+		client = httpclient.New(httpclient.Options{})
+	}
+
+	return &searchUseCase{
+		client: client,
+		parser: parser,
+	}
+}
+
+func (ucase *searchUseCase) Search(ctx context.Context, query string) ([]domain.Feed, error) {
+	u, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := ucase.fetch(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch searching page: %w", err)
+	}
+
+	if mediaType := feed.MediaType(page.contentType); mediaType != "text/html" &&
+		mediaType != "application/xhtml+xml" {
+		// query points directly to the feed
+		result, _, err := ucase.parser.Parse(page.contentType, page.body, page.url)
+		if err != nil {
+			return make([]domain.Feed, 0), nil
+		}
+
+		return []domain.Feed{*result}, nil
+	}
+
+	out := make([]domain.Feed, 0)
+
+	candidates, icon := discover(page.body, page.url)
+
+	// page itself is a feed if it contains h-feed or h-entry markup
+	if result, _, err := ucase.parser.Parse(page.contentType, page.body, page.url); err == nil {
+		if result.Photo == "" {
+			result.Photo = icon
+		}
+
+		out = append(out, *result)
+	}
+
+	for i, c := range candidates {
+		if contains(out, c.url) {
+			continue
+		}
+
+		result := &domain.Feed{URL: c.url}
+
+		// fetch feed to know its real name and photo
+		if i < maxCandidates {
+			if doc, err := ucase.fetch(ctx, c.url); err == nil {
+				if parsed, _, err := ucase.parser.Parse(doc.contentType, doc.body, doc.url); err == nil {
+					result.Name, result.Photo = parsed.Name, parsed.Photo
+				}
+			}
+		}
+
+		if result.Name == "" {
+			result.Name = c.title
+		}
+
+		if result.Photo == "" {
+			result.Photo = icon
+		}
+
+		out = append(out, *result)
+	}
+
+	return out, nil
+}
+
+func (ucase *searchUseCase) fetch(ctx context.Context, u *url.URL) (*document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "text/html, application/feed+json, application/atom+xml, application/rss+xml, "+
+		"*/*;q=0.1")
+
+	resp, err := ucase.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("cannot fetch %s: unexpected status %s", u, resp.Status)
+	}
+
+	out := &document{
+		url:         resp.Request.URL,
+		contentType: resp.Header.Get("Content-Type"),
+	}
+
+	if out.body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodySize)); err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", u, err)
+	}
+
+	return out, nil
+}
+
+// parseQuery parses URL or bare domain name of searching site.
+func parseQuery(query string) (*url.URL, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, search.ErrQuery
+	}
+
+	if !strings.Contains(query, "://") {
+		query = "https://" + query
+	}
+
+	out, err := url.Parse(query)
+	if err != nil || (out.Scheme != "http" && out.Scheme != "https") || out.Hostname() == "" {
+		return nil, fmt.Errorf("%w: %s", search.ErrQuery, query)
+	}
+
+	if out.Path == "" {
+		out.Path = "/"
+	}
+
+	return out, nil
+}
+
+// discover returns feeds linked from HTML page by rel=alternate and rel=feed
+// links and the page icon.
+func discover(body []byte, base *url.URL) ([]candidate, string) {
+	out := make([]candidate, 0)
+	icon := ""
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return out, icon
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.DataAtom != atom.Link && token.DataAtom != atom.A {
+				continue
+			}
+
+			attrs := make(map[string]string)
+			for _, a := range token.Attr {
+				attrs[a.Key] = a.Val
+			}
+
+			href, err := base.Parse(strings.TrimSpace(attrs["href"]))
+			if err != nil || attrs["href"] == "" {
+				continue
+			}
+
+			rels := strings.Fields(strings.ToLower(attrs["rel"]))
+
+			switch {
+			case slices.Contains(rels, "alternate") && feedTypes[feed.MediaType(attrs["type"])],
+				slices.Contains(rels, "feed"):
+				out = append(out, candidate{url: href, title: strings.TrimSpace(attrs["title"])})
+			case token.DataAtom != atom.Link:
+			case slices.Contains(rels, "icon"), slices.Contains(rels, "apple-touch-icon"):
+				if icon == "" || slices.Contains(rels, "apple-touch-icon") {
+					icon = href.String()
+				}
+			}
+		}
+	}
+}
+
+func contains(feeds []domain.Feed, u *url.URL) bool {
+	for i := range feeds {
+		if feeds[i].URL.String() == u.String() {
+			return true
+		}
+	}
+
+	return false
+}
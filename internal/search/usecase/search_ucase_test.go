@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/feed/mf2"
+	"source.toby3d.me/toby3d/sub/internal/feed/rss"
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+	"source.toby3d.me/toby3d/sub/internal/search"
+	ucase "source.toby3d.me/toby3d/sub/internal/search/usecase"
+)
+
+func TestSearchUseCase_Search(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<!DOCTYPE html>
+<html>
+<head>
+	<link rel="icon" href="/icon.png">
+	<link rel="alternate" type="application/rss+xml" title="Posts" href="/feed.xml">
+	<link rel="alternate" type="text/html" hreflang="ru" href="/ru/">
+</head>
+<body><a rel="feed" href="/notes">Notes</a></body>
+</html>`)
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Example posts</title></channel></rss>`)
+	})
+	mux.HandleFunc("/notes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<div class="h-feed"><h1 class="p-name">Example notes</h1></div>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	actual, err := ucase.NewSearchUseCase(srv.Client(), feed.NewParser(rss.Format{}, mf2.Format{})).
+		Search(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make([][3]string, 0, len(actual))
+	for _, f := range actual {
+		result = append(result, [3]string{f.URL.String(), f.Name, f.Photo})
+	}
+
+	expect := [][3]string{
+		{srv.URL + "/feed.xml", "Example posts", srv.URL + "/icon.png"},
+		{srv.URL + "/notes", "Example notes", srv.URL + "/icon.png"},
+	}
+
+	if diff := cmp.Diff(expect, result); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSearchUseCase_Search_Query(t *testing.T) {
+	t.Parallel()
+
+	for _, query := range []string{"", "https://", "ftp://example.com/"} {
+		query := query
+
+		t.Run(query, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ucase.NewSearchUseCase(nil, feed.NewParser()).
+				Search(context.Background(), query); !errors.Is(err, search.ErrQuery) {
+				t.Errorf("expect %v, got %v", search.ErrQuery, err)
+			}
+		})
+	}
+}
+
+func TestSearchUseCase_Search_Private(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("want no requests to loopback address")
+	}))
+	t.Cleanup(srv.Close)
+
+	if _, err := ucase.NewSearchUseCase(nil, feed.NewParser()).
+		Search(context.Background(), srv.URL); !errors.Is(err, httpclient.ErrPrivate) {
+		t.Errorf("expect %v, got %v", httpclient.ErrPrivate, err)
+	}
+}
@@ -0,0 +1,25 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type (
+	UpdateFunc func(entry *domain.Entry) (*domain.Entry, error)
+
+	Repository interface {
+		Create(ctx context.Context, user domain.User, entry domain.Entry) error
+		Get(ctx context.Context, user domain.User, id string) (*domain.Entry, error)
+		Fetch(ctx context.Context, user domain.User, channel string) ([]domain.Entry, error)
+		Update(ctx context.Context, user domain.User, id string, update UpdateFunc) error
+		Delete(ctx context.Context, user domain.User, id string) error
+	}
+)
+
+var (
+	ErrNotExist = errors.New("entry does not exist")
+	ErrExist    = errors.New("entry already exists")
+)
@@ -0,0 +1,223 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+
+	database "source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+)
+
+type boltTimelineRepository struct {
+	db *bolt.DB
+}
+
+var (
+	// bucketEntries contains entries by their ID.
+	bucketEntries = []byte("entries")
+
+	// bucketTimelines contains nested bucket of entries IDs for each
+	// channel.
+	bucketTimelines = []byte("timelines")
+)
+
+// NewBoltTimelineRepository creates entries repository in database opened by
+// bolt.Open.
+func NewBoltTimelineRepository(db *bolt.DB) timeline.Repository {
+	return &boltTimelineRepository{
+		db: db,
+	}
+}
+
+func (repo *boltTimelineRepository) Create(ctx context.Context, u domain.User, e domain.Entry) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		entries, timelines, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		if entries.Get([]byte(e.ID)) != nil {
+			return timeline.ErrExist
+		}
+
+		return put(entries, timelines, e)
+	})
+}
+
+func (repo *boltTimelineRepository) Get(ctx context.Context, u domain.User, id string) (*domain.Entry, error) {
+	var out *domain.Entry
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		entries, _, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		out, err = get(entries, id)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (repo *boltTimelineRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Entry, error) {
+	out := make([]domain.Entry, 0)
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		entries, timelines, err := buckets(tx, u)
+		if err != nil || timelines == nil {
+			return err
+		}
+
+		ids := timelines.Bucket([]byte(cid))
+		if ids == nil {
+			return nil
+		}
+
+		return ids.ForEach(func(id, _ []byte) error {
+			e, err := get(entries, string(id))
+			if err != nil {
+				return err
+			}
+
+			out = append(out, *e)
+
+			return nil
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("cannot fetch entries: %w", err)
+	}
+
+	return out, nil
+}
+
+func (repo *boltTimelineRepository) Update(ctx context.Context, u domain.User, id string, update timeline.UpdateFunc) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		entries, timelines, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		in, err := get(entries, id)
+		if err != nil {
+			return fmt.Errorf("cannot find updating entry: %w", err)
+		}
+
+		if err = unlink(timelines, *in); err != nil {
+			return err
+		}
+
+		out, err := update(in)
+		if err != nil {
+			// returned error rollbacks the whole transaction
+			return fmt.Errorf("cannot update entry: %w", err)
+		}
+
+		out.ID = id
+
+		return put(entries, timelines, *out)
+	})
+}
+
+func (repo *boltTimelineRepository) Delete(ctx context.Context, u domain.User, id string) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		entries, timelines, err := buckets(tx, u)
+		if err != nil {
+			return err
+		}
+
+		e, err := get(entries, id)
+		if err != nil {
+			// deleting of unknown entry is not an error
+			return nil
+		}
+
+		if err = unlink(timelines, *e); err != nil {
+			return err
+		}
+
+		return entries.Delete([]byte(id))
+	})
+}
+
+// buckets returns entries and timelines buckets of user. Both of them are nil
+// in read-only transaction if user has no entries yet.
+func buckets(tx *bolt.Tx, u domain.User) (*bolt.Bucket, *bolt.Bucket, error) {
+	entries, err := database.UserBucket(tx, u, bucketEntries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timelines, err := database.UserBucket(tx, u, bucketTimelines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, timelines, nil
+}
+
+func get(entries *bolt.Bucket, id string) (*domain.Entry, error) {
+	if entries == nil {
+		return nil, timeline.ErrNotExist
+	}
+
+	src := entries.Get([]byte(id))
+	if src == nil {
+		return nil, timeline.ErrNotExist
+	}
+
+	out := new(domain.Entry)
+	if err := json.Unmarshal(src, out); err != nil {
+		return nil, fmt.Errorf("cannot decode entry: %w", err)
+	}
+
+	return out, nil
+}
+
+func put(entries, timelines *bolt.Bucket, e domain.Entry) error {
+	src, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("cannot encode entry: %w", err)
+	}
+
+	if err = entries.Put([]byte(e.ID), src); err != nil {
+		return fmt.Errorf("cannot store entry: %w", err)
+	}
+
+	// entry outside of any channel can be found only by ID
+	if e.Channel == "" {
+		return nil
+	}
+
+	ids, err := timelines.CreateBucketIfNotExists([]byte(e.Channel))
+	if err != nil {
+		return fmt.Errorf("cannot create timeline bucket: %w", err)
+	}
+
+	if err = ids.Put([]byte(e.ID), []byte{}); err != nil {
+		return fmt.Errorf("cannot store entry in timeline: %w", err)
+	}
+
+	return nil
+}
+
+// unlink removes entry from the timeline of its channel.
+func unlink(timelines *bolt.Bucket, e domain.Entry) error {
+	ids := timelines.Bucket([]byte(e.Channel))
+	if ids == nil {
+		return nil
+	}
+
+	if err := ids.Delete([]byte(e.ID)); err != nil {
+		return fmt.Errorf("cannot remove entry from timeline: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package bolt_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	repository "source.toby3d.me/toby3d/sub/internal/timeline/repository/bolt"
+)
+
+func TestBoltTimelineRepository(t *testing.T) {
+	t.Parallel()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "sub.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	user := domain.TestUser(t)
+	entries := repository.NewBoltTimelineRepository(db)
+	entry := domain.TestEntry(t)
+	entry.Channel = "home"
+
+	if err = entries.Create(ctx, *user, *entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = entries.Create(ctx, *user, *entry); !errors.Is(err, timeline.ErrExist) {
+		t.Errorf("want %v, got %v", timeline.ErrExist, err)
+	}
+
+	actual, err := entries.Get(ctx, *user, entry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(entry, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	errAbort := errors.New("abort")
+	if err = entries.Update(ctx, *user, entry.ID, func(tx *domain.Entry) (*domain.Entry, error) {
+		tx.IsRead = true
+
+		return nil, errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Errorf("want %v, got %v", errAbort, err)
+	}
+
+	// moved entry leaves timeline of previous channel
+	if err = entries.Update(ctx, *user, entry.ID, func(tx *domain.Entry) (*domain.Entry, error) {
+		tx.Channel = "other"
+
+		return tx, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for cid, expect := range map[string]int{"home": 0, "other": 1} {
+		result, err := entries.Fetch(ctx, *user, cid)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != expect {
+			t.Errorf("want %d entries in %s channel, got %d", expect, cid, len(result))
+		}
+
+		for i := range result {
+			if result[i].IsRead {
+				t.Error("want unread entry after failed update, got read")
+			}
+		}
+	}
+
+	if err = entries.Delete(ctx, *user, entry.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = entries.Get(ctx, *user, entry.ID); !errors.Is(err, timeline.ErrNotExist) {
+		t.Errorf("want %v, got %v", timeline.ErrNotExist, err)
+	}
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+)
+
+type memoryTimelineRepository struct {
+	mutex   *sync.RWMutex
+	entries map[string][]domain.Entry
+}
+
+func NewMemoryTimelineRepository() timeline.Repository {
+	return &memoryTimelineRepository{
+		mutex:   new(sync.RWMutex),
+		entries: make(map[string][]domain.Entry, 0),
+	}
+}
+
+func (repo *memoryTimelineRepository) Create(ctx context.Context, u domain.User, e domain.Entry) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if repo.index(u, e.ID) != -1 {
+		return timeline.ErrExist
+	}
+
+	repo.entries[u.String()] = append(repo.entries[u.String()], e)
+
+	return nil
+}
+
+func (repo *memoryTimelineRepository) Get(ctx context.Context, u domain.User, id string) (*domain.Entry, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	i := repo.index(u, id)
+	if i == -1 {
+		return nil, timeline.ErrNotExist
+	}
+
+	out := repo.entries[u.String()][i]
+
+	return &out, nil
+}
+
+func (repo *memoryTimelineRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Entry, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	out := make([]domain.Entry, 0)
+
+	for _, e := range repo.entries[u.String()] {
+		if e.Channel != cid {
+			continue
+		}
+
+		out = append(out, e)
+	}
+
+	return out, nil
+}
+
+func (repo *memoryTimelineRepository) Update(ctx context.Context, u domain.User, id string, update timeline.UpdateFunc) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	i := repo.index(u, id)
+	if i == -1 {
+		return fmt.Errorf("cannot find updating entry: %w", timeline.ErrNotExist)
+	}
+
+	in := repo.entries[u.String()][i]
+
+	out, err := update(&in)
+	if err != nil {
+		return fmt.Errorf("cannot update entry: %w", err)
+	}
+
+	repo.entries[u.String()][i] = *out
+
+	return nil
+}
+
+func (repo *memoryTimelineRepository) Delete(ctx context.Context, u domain.User, id string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if i := repo.index(u, id); i != -1 {
+		repo.entries[u.String()] = slices.Delete(repo.entries[u.String()], i, i+1)
+	}
+
+	return nil
+}
+
+// index returns position of user entry by its ID or -1 if there is none. Must
+// be called under lock.
+func (repo *memoryTimelineRepository) index(u domain.User, id string) int {
+	entries := repo.entries[u.String()]
+
+	for i := range entries {
+		if entries[i].ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
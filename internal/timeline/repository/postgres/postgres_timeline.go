@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+)
+
+type (
+	postgresTimelineRepository struct {
+		db *sqlx.DB
+	}
+
+	// Entry is a row of entries table. Entry itself is stored as JSON, only
+	// the columns needed for lookups are separated. Data is a string,
+	// because driver sends bytes as bytea which is not accepted by JSONB.
+	Entry struct {
+		Me      string `db:"me"`
+		ID      string `db:"id"`
+		Channel string `db:"channel"`
+		Data    string `db:"data"`
+	}
+)
+
+const (
+	queryTable  string = "SELECT me, id, channel, data FROM entries"
+	queryGet    string = queryTable + " WHERE me = $1 AND id = $2"
+	queryFetch  string = queryTable + " WHERE me = $1 AND channel = $2"
+	queryCreate string = `INSERT INTO entries (me, id, channel, data) VALUES (:me, :id, :channel, :data)
+		ON CONFLICT (me, id) DO NOTHING`
+	queryUpdate string = "UPDATE entries SET channel = :channel, data = :data WHERE me = :me AND id = :id"
+	queryDelete string = "DELETE FROM entries WHERE me = $1 AND id = $2"
+)
+
+// NewPostgresTimelineRepository creates entries repository in database opened
+// by postgres.Open.
+func NewPostgresTimelineRepository(db *sqlx.DB) timeline.Repository {
+	return &postgresTimelineRepository{
+		db: db,
+	}
+}
+
+func (repo *postgresTimelineRepository) Create(ctx context.Context, u domain.User, e domain.Entry) error {
+	row, err := NewEntry(u, e)
+	if err != nil {
+		return err
+	}
+
+	result, err := repo.db.NamedExecContext(ctx, queryCreate, row)
+	if err != nil {
+		return fmt.Errorf("cannot create entry: %w", err)
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return timeline.ErrExist
+	}
+
+	return nil
+}
+
+func (repo *postgresTimelineRepository) Get(ctx context.Context, u domain.User, id string) (*domain.Entry, error) {
+	return get(ctx, repo.db, u, id, "")
+}
+
+func (repo *postgresTimelineRepository) Fetch(ctx context.Context, u domain.User, cid string) ([]domain.Entry, error) {
+	rows := make([]Entry, 0)
+	if err := repo.db.SelectContext(ctx, &rows, queryFetch, u.String(), cid); err != nil {
+		return nil, fmt.Errorf("cannot fetch entries: %w", err)
+	}
+
+	out := make([]domain.Entry, 0, len(rows))
+
+	for i := range rows {
+		e, err := rows[i].Populate()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, *e)
+	}
+
+	return out, nil
+}
+
+func (repo *postgresTimelineRepository) Update(ctx context.Context, u domain.User, id string, update timeline.UpdateFunc) error {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	in, err := get(ctx, tx, u, id, " FOR UPDATE")
+	if err != nil {
+		return fmt.Errorf("cannot find updating entry: %w", err)
+	}
+
+	out, err := update(in)
+	if err != nil {
+		return fmt.Errorf("cannot update entry: %w", err)
+	}
+
+	out.ID = id
+
+	row, err := NewEntry(u, *out)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.NamedExecContext(ctx, queryUpdate, row); err != nil {
+		return fmt.Errorf("cannot update entry: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("cannot commit updated entry: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *postgresTimelineRepository) Delete(ctx context.Context, u domain.User, id string) error {
+	if _, err := repo.db.ExecContext(ctx, queryDelete, u.String(), id); err != nil {
+		return fmt.Errorf("cannot delete entry: %w", err)
+	}
+
+	return nil
+}
+
+func NewEntry(u domain.User, e domain.Entry) (*Entry, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode entry: %w", err)
+	}
+
+	return &Entry{
+		Me:      u.String(),
+		ID:      e.ID,
+		Channel: e.Channel,
+		Data:    string(data),
+	}, nil
+}
+
+func (e Entry) Populate() (*domain.Entry, error) {
+	out := new(domain.Entry)
+	if err := json.Unmarshal([]byte(e.Data), out); err != nil {
+		return nil, fmt.Errorf("cannot decode entry: %w", err)
+	}
+
+	return out, nil
+}
+
+func get(ctx context.Context, db sqlx.QueryerContext, u domain.User, id, suffix string) (*domain.Entry, error) {
+	row := new(Entry)
+	if err := sqlx.GetContext(ctx, db, row, queryGet+suffix, u.String(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, timeline.ErrNotExist
+		}
+
+		return nil, fmt.Errorf("cannot get entry: %w", err)
+	}
+
+	return row.Populate()
+}
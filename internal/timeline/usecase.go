@@ -0,0 +1,49 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type UseCase interface {
+	// Create stores new entries into the user channel and returns only
+	// created entries. Already known entries are matched by UID, canonical
+	// URL or content hash: edited ones are updated in place keeping their
+	// ID and read state, unchanged and removed ones are skipped.
+	Create(ctx context.Context, u domain.User, channel string, entries ...domain.Entry) ([]domain.Entry, error)
+
+	// Fetch returns a single page of channel entries starting from the
+	// newest one or around one of the provided paging cursors.
+	Fetch(ctx context.Context, u domain.User, channel string, paging domain.Paging) (*domain.Timeline, error)
+
+	// MarkRead marks channel entries by their IDs as read. Unknown IDs are
+	// ignored.
+	MarkRead(ctx context.Context, u domain.User, channel string, ids ...string) error
+
+	// MarkUnread marks channel entries by their IDs as unread. Unknown IDs
+	// are ignored.
+	MarkUnread(ctx context.Context, u domain.User, channel string, ids ...string) error
+
+	// MarkReadBefore marks the channel entry by ID and all older entries as
+	// read.
+	MarkReadBefore(ctx context.Context, u domain.User, channel string, id string) error
+
+	// Remove hides channel entries by their IDs permanently. Removed
+	// entries are not delivered again by Create. Unknown IDs are ignored.
+	Remove(ctx context.Context, u domain.User, channel string, ids ...string) error
+
+	// Unread returns count of unread entries in channel.
+	Unread(ctx context.Context, u domain.User, channel string) (int, error)
+
+	// Compact removes channel entries which are out of retention policy at
+	// the moment now and returns their count. Compacted entries are not
+	// delivered again by Create while they are among the newest entries of
+	// their source, older ones are forgotten completely. Entries of muted
+	// users are not counted by MaxCount.
+	Compact(ctx context.Context, u domain.User, channel string, policy domain.Retention, now time.Time) (int, error)
+}
+
+var ErrCursor = errors.New("invalid paging cursor")
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+// entryIndex finds already stored entries of channel by their identities.
+type entryIndex struct {
+	uids   map[string]domain.Entry
+	urls   map[string]domain.Entry
+	hashes map[string]domain.Entry
+}
+
+func newEntryIndex(entries []domain.Entry) *entryIndex {
+	out := &entryIndex{
+		uids:   make(map[string]domain.Entry, len(entries)),
+		urls:   make(map[string]domain.Entry, len(entries)),
+		hashes: make(map[string]domain.Entry, len(entries)),
+	}
+
+	for i := range entries {
+		out.add(entries[i])
+	}
+
+	return out
+}
+
+// add remembers entry by all of its identities.
+func (idx *entryIndex) add(e domain.Entry) {
+	if e.UID != "" {
+		idx.uids[e.UID] = e
+	}
+
+	if u := canonicalURL(e.URL); u != "" {
+		idx.urls[u] = e
+	}
+
+	if hash := entryHash(e); hash != "" {
+		idx.hashes[hash] = e
+	}
+}
+
+// find returns stored entry matched by UID, then by canonical URL and then by
+// content hash, or nil if entry is new. Content hash is used only for entries
+// without any UID or URL, otherwise different posts with the same text would
+// collapse into one.
+func (idx *entryIndex) find(e domain.Entry) *domain.Entry {
+	if e.UID != "" {
+		if out, ok := idx.uids[e.UID]; ok {
+			return &out
+		}
+	}
+
+	if u := canonicalURL(e.URL); u != "" {
+		if out, ok := idx.urls[u]; ok {
+			return &out
+		}
+	}
+
+	if e.UID != "" || e.URL != "" {
+		return nil
+	}
+
+	if out, ok := idx.hashes[contentHash(e)]; ok {
+		return &out
+	}
+
+	return nil
+}
+
+// canonicalURL normalizes src, so the same page linked by different ways has
+// the same URL: scheme and host are lowercased, default port, fragment and
+// tracking parameters are dropped, query is sorted.
+func canonicalURL(src string) string {
+	if src == "" {
+		return ""
+	}
+
+	u, err := url.Parse(strings.TrimSpace(src))
+	if err != nil || u.Host == "" {
+		return src
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment, u.RawFragment = "", ""
+
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	q := u.Query()
+	for key := range q {
+		if strings.HasPrefix(key, "utm_") {
+			q.Del(key)
+		}
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// entryHash returns stored hash of entry content, or computes it for entries
+// stored without one. Removed entries without stored hash have no content to
+// compute it from.
+func entryHash(e domain.Entry) string {
+	if e.Hash != "" || e.IsRemoved {
+		return e.Hash
+	}
+
+	return contentHash(e)
+}
+
+// contentHash returns hash of the published content of entry, ignoring its
+// state inside channel.
+func contentHash(e domain.Entry) string {
+	hash := sha256.New()
+
+	for _, field := range [][]string{
+		{e.Published.UTC().Format(time.RFC3339Nano), e.Updated.UTC().Format(time.RFC3339Nano)},
+		{e.Name, e.Summary},
+		contentFields(e.Content),
+		cardFields(e.Author),
+		cardFields(e.Checkin),
+		e.Photo, e.Video, e.Audio,
+		e.LikeOf, e.RepostOf, e.BookmarkOf, e.InReplyTo,
+		e.Category,
+	} {
+		for _, v := range field {
+			// separate values, so moved text between fields changes hash
+			hash.Write([]byte(v))
+			hash.Write([]byte{0})
+		}
+
+		hash.Write([]byte{1})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func contentFields(c *domain.Content) []string {
+	if c == nil {
+		return nil
+	}
+
+	return []string{c.Text, c.HTML}
+}
+
+func cardFields(c *domain.Card) []string {
+	if c == nil {
+		return nil
+	}
+
+	return []string{c.Name, c.URL, c.Photo}
+}
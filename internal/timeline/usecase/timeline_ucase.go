@@ -0,0 +1,571 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	authormemoryrepo "source.toby3d.me/toby3d/sub/internal/author/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/event"
+	eventmemory "source.toby3d.me/toby3d/sub/internal/event/memory"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+)
+
+type (
+	timelineUseCase struct {
+		entries timeline.Repository
+		mutes   author.Repository
+		blocks  author.Repository
+		events  event.Bus
+	}
+
+	NewTimelineUseCaseOptions struct {
+		Entries timeline.Repository
+
+		// Mutes contains users whose entries are stored but hidden.
+		// Nobody is muted if nil.
+		Mutes author.Repository
+
+		// Blocks contains users whose entries are not stored at all.
+		// Nobody is blocked if nil.
+		Blocks author.Repository
+
+		// Events receives new entries and read state changes. Events
+		// are discarded if nil.
+		Events event.Bus
+	}
+
+	// cursor points to the position of entry in sorted timeline. Entries are
+	// ordered by publish date and then by ID, so cursor stays valid when new
+	// entries arrive.
+	cursor struct {
+		published time.Time
+		id        string
+	}
+)
+
+// limit is a maximum number of entries in a single timeline page.
+const limit int = 20
+
+// sourceWindow is a number of the newest entries of the same source, which
+// keep their tombstones. Older entries are out of the source feed already, so
+// they are not delivered again and their tombstones are deleted on compaction.
+const sourceWindow int = 100
+
+func NewTimelineUseCase(opts NewTimelineUseCaseOptions) timeline.UseCase {
+	out := &timelineUseCase{
+		entries: opts.Entries,
+		mutes:   opts.Mutes,
+		blocks:  opts.Blocks,
+		events:  opts.Events,
+	}
+
+	if out.mutes == nil {
+		out.mutes = authormemoryrepo.NewMemoryAuthorRepository()
+	}
+
+	if out.blocks == nil {
+		out.blocks = authormemoryrepo.NewMemoryAuthorRepository()
+	}
+
+	if out.events == nil {
+		out.events = eventmemory.NewMemoryEventBus()
+	}
+
+	return out
+}
+
+// Create stores new entries into channel and updates the known ones in place.
+// Entries are matched by UID, then by canonical URL and then by content hash,
+// so the same post is never duplicated. Updated entries keep their ID and read
+// state, removed entries stay removed.
+func (ucase *timelineUseCase) Create(ctx context.Context, u domain.User, cid string, entries ...domain.Entry) ([]domain.Entry, error) {
+	known, err := ucase.entries.Fetch(ctx, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch known timeline entries: %w", err)
+	}
+
+	index := newEntryIndex(known)
+
+	blocked, err := ucase.authors(ctx, ucase.blocks.Fetch, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch blocked users: %w", err)
+	}
+
+	// entries of muted users are stored, but nobody is notified about them
+	muted, err := ucase.authors(ctx, ucase.mutes.Fetch, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch muted users: %w", err)
+	}
+
+	out := make([]domain.Entry, 0, len(entries))
+
+	for _, e := range entries {
+		if e.Author != nil {
+			if _, ok := blocked[e.Author.URL]; ok {
+				continue
+			}
+		}
+
+		e.Content = sanitize(e)
+
+		if existing := index.find(e); existing != nil {
+			e.Hash = contentHash(e)
+			if existing.IsRemoved || entryHash(*existing) == e.Hash {
+				continue
+			}
+
+			if err = ucase.entries.Update(ctx, u, existing.ID, func(tx *domain.Entry) (*domain.Entry, error) {
+				e.ID, e.Channel, e.IsRead = tx.ID, tx.Channel, tx.IsRead
+
+				return &e, nil
+			}); err != nil {
+				return out, fmt.Errorf("cannot update edited entry: %w", err)
+			}
+
+			index.add(e)
+
+			continue
+		}
+
+		id := make([]byte, 16)
+		if _, err = rand.Read(id); err != nil {
+			return out, fmt.Errorf("cannot generate ID for new entry: %w", err)
+		}
+
+		e.ID = hex.EncodeToString(id)
+		e.Channel = cid
+		e.Hash = contentHash(e)
+
+		if err = ucase.entries.Create(ctx, u, e); err != nil {
+			return out, fmt.Errorf("cannot create entry: %w", err)
+		}
+
+		index.add(e)
+		out = append(out, e)
+
+		if e.Author != nil {
+			if _, ok := muted[e.Author.URL]; ok {
+				continue
+			}
+		}
+
+		// event outlives the iteration, so it must not point to e
+		entry := e
+
+		ucase.events.Publish(ctx, u, domain.Event{
+			Type:    domain.EventTypeNewItem,
+			Channel: domain.Channel{UID: cid},
+			Entry:   &entry,
+		})
+	}
+
+	return out, nil
+}
+
+func (ucase *timelineUseCase) Fetch(ctx context.Context, u domain.User, cid string, paging domain.Paging) (*domain.Timeline, error) {
+	entries, err := ucase.entries.Fetch(ctx, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch timeline entries: %w", err)
+	}
+
+	// entries of muted users are still stored, so unmute restores them
+	if entries, err = ucase.visible(ctx, u, cid, entries); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return newCursor(entries[i]).newer(newCursor(entries[j]))
+	})
+
+	out := new(domain.Timeline)
+
+	switch {
+	default:
+		out.Items = entries[:clamp(limit, len(entries))]
+	case paging.Before != "":
+		c, err := parseCursor(paging.Before)
+		if err != nil {
+			return nil, err
+		}
+
+		// entries newer than cursor are the head of the slice, so take
+		// the closest ones to the cursor
+		end := sort.Search(len(entries), func(i int) bool {
+			return !newCursor(entries[i]).newer(*c)
+		})
+
+		out.Items = entries[end-clamp(limit, end) : end]
+		out.Paging.Before = paging.Before
+	case paging.After != "":
+		c, err := parseCursor(paging.After)
+		if err != nil {
+			return nil, err
+		}
+
+		start := sort.Search(len(entries), func(i int) bool {
+			return c.newer(newCursor(entries[i]))
+		})
+
+		out.Items = entries[start : start+clamp(limit, len(entries)-start)]
+	}
+
+	if len(out.Items) == 0 {
+		return out, nil
+	}
+
+	out.Paging.Before = newCursor(out.Items[0]).String()
+
+	if last := out.Items[len(out.Items)-1]; newCursor(last).newer(newCursor(entries[len(entries)-1])) {
+		out.Paging.After = newCursor(last).String()
+	}
+
+	return out, nil
+}
+
+func (ucase *timelineUseCase) MarkRead(ctx context.Context, u domain.User, cid string, ids ...string) error {
+	return ucase.mark(ctx, u, cid, true, ids...)
+}
+
+func (ucase *timelineUseCase) MarkUnread(ctx context.Context, u domain.User, cid string, ids ...string) error {
+	return ucase.mark(ctx, u, cid, false, ids...)
+}
+
+func (ucase *timelineUseCase) MarkReadBefore(ctx context.Context, u domain.User, cid, id string) error {
+	last, err := ucase.entries.Get(ctx, u, id)
+	if err != nil {
+		return fmt.Errorf("cannot find last read entry: %w", err)
+	}
+
+	if last.Channel != cid {
+		return fmt.Errorf("cannot find last read entry: %w", timeline.ErrNotExist)
+	}
+
+	entries, err := ucase.entries.Fetch(ctx, u, cid)
+	if err != nil {
+		return fmt.Errorf("cannot fetch timeline entries: %w", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for i := range entries {
+		if !entries[i].IsRead && !newCursor(entries[i]).newer(newCursor(*last)) {
+			ids = append(ids, entries[i].ID)
+		}
+	}
+
+	return ucase.mark(ctx, u, cid, true, ids...)
+}
+
+func (ucase *timelineUseCase) Unread(ctx context.Context, u domain.User, cid string) (int, error) {
+	entries, err := ucase.entries.Fetch(ctx, u, cid)
+	if err != nil {
+		return 0, fmt.Errorf("cannot fetch timeline entries: %w", err)
+	}
+
+	// removed and muted entries are invisible, so they cannot be read
+	if entries, err = ucase.visible(ctx, u, cid, entries); err != nil {
+		return 0, err
+	}
+
+	out := 0
+
+	for i := range entries {
+		if !entries[i].IsRead {
+			out++
+		}
+	}
+
+	return out, nil
+}
+
+func (ucase *timelineUseCase) Remove(ctx context.Context, u domain.User, cid string, ids ...string) error {
+	for _, id := range ids {
+		if err := ucase.entries.Update(ctx, u, id, func(tx *domain.Entry) (*domain.Entry, error) {
+			if tx.Channel != cid {
+				return nil, timeline.ErrNotExist
+			}
+
+			return tombstone(*tx), nil
+		}); err != nil {
+			if errors.Is(err, timeline.ErrNotExist) {
+				continue
+			}
+
+			return fmt.Errorf("cannot remove entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (ucase *timelineUseCase) Compact(ctx context.Context, u domain.User, cid string, policy domain.Retention,
+	now time.Time,
+) (int, error) {
+	if policy.IsZero() {
+		return 0, nil
+	}
+
+	entries, err := ucase.entries.Fetch(ctx, u, cid)
+	if err != nil {
+		return 0, fmt.Errorf("cannot fetch timeline entries: %w", err)
+	}
+
+	muted, err := ucase.authors(ctx, ucase.mutes.Fetch, u, cid)
+	if err != nil {
+		return 0, fmt.Errorf("cannot fetch muted users: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return newCursor(entries[i]).newer(newCursor(entries[j]))
+	})
+
+	out, shown := 0, 0
+
+	// newer contains count of already seen entries of each source
+	newer := make(map[string]int)
+
+	for i := range entries {
+		position := newer[entries[i].Source]
+		newer[entries[i].Source]++
+
+		if entries[i].IsRemoved {
+			if position < sourceWindow {
+				continue
+			}
+
+			if err = ucase.entries.Delete(ctx, u, entries[i].ID); err != nil &&
+				!errors.Is(err, timeline.ErrNotExist) {
+				return out, fmt.Errorf("cannot prune removed entry: %w", err)
+			}
+
+			continue
+		}
+
+		// muted entries are hidden, so they do not take places of shown
+		// ones and leave only by age
+		hidden, overflow := false, false
+		if entries[i].Author != nil {
+			_, hidden = muted[entries[i].Author.URL]
+		}
+
+		if !hidden {
+			overflow = policy.MaxCount > 0 && shown >= policy.MaxCount
+			shown++
+		}
+
+		if policy.KeepUnread && !entries[i].IsRead {
+			continue
+		}
+
+		expired := policy.MaxAge > 0 && !entries[i].Published.IsZero() &&
+			now.Sub(entries[i].Published) > policy.MaxAge
+		if !expired && !overflow {
+			continue
+		}
+
+		// compacted entry becomes a tombstone, otherwise the next poll
+		// of source delivers it again
+		if err = ucase.entries.Update(ctx, u, entries[i].ID, func(tx *domain.Entry) (*domain.Entry, error) {
+			return tombstone(*tx), nil
+		}); err != nil {
+			if errors.Is(err, timeline.ErrNotExist) {
+				continue
+			}
+
+			return out, fmt.Errorf("cannot compact entry: %w", err)
+		}
+
+		out++
+	}
+
+	return out, nil
+}
+
+// mark sets read state of channel entries.
+func (ucase *timelineUseCase) mark(ctx context.Context, u domain.User, cid string, read bool, ids ...string) error {
+	marked := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if err := ucase.entries.Update(ctx, u, id, func(tx *domain.Entry) (*domain.Entry, error) {
+			if tx.Channel != cid {
+				return nil, timeline.ErrNotExist
+			}
+
+			tx.IsRead = read
+
+			return tx, nil
+		}); err != nil {
+			if errors.Is(err, timeline.ErrNotExist) {
+				continue
+			}
+
+			return fmt.Errorf("cannot mark entry read state: %w", err)
+		}
+
+		marked = append(marked, id)
+	}
+
+	if len(marked) == 0 {
+		return nil
+	}
+
+	e := domain.Event{
+		Type:    domain.EventTypeMarkUnread,
+		Channel: domain.Channel{UID: cid},
+		Entries: marked,
+	}
+
+	if read {
+		e.Type = domain.EventTypeMarkRead
+	}
+
+	ucase.events.Publish(ctx, u, e)
+
+	return nil
+}
+
+// tombstone keeps only the data of entry needed to recognize it on next
+// delivery and to keep cursors valid.
+func tombstone(e domain.Entry) *domain.Entry {
+	return &domain.Entry{
+		Published: e.Published,
+		ID:        e.ID,
+		Channel:   e.Channel,
+		Source:    e.Source,
+		UID:       e.UID,
+		URL:       e.URL,
+		Hash:      entryHash(e),
+		IsRead:    true,
+		IsRemoved: true,
+	}
+}
+
+// visible filters out removed entries and entries of users muted in channel or
+// globally.
+func (ucase *timelineUseCase) visible(ctx context.Context, u domain.User, cid string, entries []domain.Entry) (
+	[]domain.Entry, error,
+) {
+	muted, err := ucase.authors(ctx, ucase.mutes.Fetch, u, cid)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch muted users: %w", err)
+	}
+
+	out := entries[:0]
+
+	for i := range entries {
+		if entries[i].IsRemoved {
+			continue
+		}
+
+		if entries[i].Author != nil {
+			if _, ok := muted[entries[i].Author.URL]; ok {
+				continue
+			}
+		}
+
+		out = append(out, entries[i])
+	}
+
+	return out, nil
+}
+
+// authors returns set of URLs of users listed by fetch in channel or globally.
+func (ucase *timelineUseCase) authors(
+	ctx context.Context,
+	fetch func(context.Context, domain.User, string) ([]domain.Card, error),
+	u domain.User,
+	cid string,
+) (map[string]struct{}, error) {
+	out := make(map[string]struct{})
+
+	for _, c := range []string{cid, common.ChannelGlobal} {
+		authors, err := fetch(ctx, u, c)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range authors {
+			out[authors[i].URL] = struct{}{}
+		}
+	}
+
+	return out, nil
+}
+
+// clamp returns n bounded by size.
+func clamp(n, size int) int {
+	if n > size {
+		return size
+	}
+
+	return n
+}
+
+func newCursor(e domain.Entry) cursor {
+	return cursor{
+		published: e.Published,
+		id:        e.ID,
+	}
+}
+
+func parseCursor(src string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", timeline.ErrCursor, src)
+	}
+
+	published, id, found := strings.Cut(string(raw), " ")
+	if !found || id == "" {
+		return nil, fmt.Errorf("%w: %s", timeline.ErrCursor, src)
+	}
+
+	out := &cursor{id: id}
+	if out.published, err = time.Parse(time.RFC3339Nano, published); err != nil {
+		return nil, fmt.Errorf("%w: %s", timeline.ErrCursor, src)
+	}
+
+	return out, nil
+}
+
+// newer reports whether c points to the position before target in timeline.
+func (c cursor) newer(target cursor) bool {
+	if !c.published.Equal(target.published) {
+		return c.published.After(target.published)
+	}
+
+	return c.id > target.id
+}
+
+func (c cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.published.Format(time.RFC3339Nano) + " " + c.id))
+}
+
+// sanitize returns a copy of entry content with markup which is safe to embed
+// into clients pages. Relative URLs in markup are resolved against the entry
+// URL.
+func sanitize(e domain.Entry) *domain.Content {
+	if e.Content == nil || e.Content.HTML == "" {
+		return e.Content
+	}
+
+	base, err := url.Parse(e.URL)
+	if err != nil || !base.IsAbs() {
+		base = nil
+	}
+
+	out := *e.Content
+	out.HTML = feed.SanitizeHTML(out.HTML, base)
+
+	return &out
+}
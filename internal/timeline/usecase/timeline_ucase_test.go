@@ -0,0 +1,787 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	authormemoryrepo "source.toby3d.me/toby3d/sub/internal/author/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	eventmemory "source.toby3d.me/toby3d/sub/internal/event/memory"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+	ucase "source.toby3d.me/toby3d/sub/internal/timeline/usecase"
+)
+
+func TestTimelineUseCase_Fetch(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	now := time.Now().UTC()
+
+	expect := make([]string, 0, 30)
+
+	for i := 0; i < 30; i++ {
+		e := domain.TestEntry(t)
+		e.Channel = channel.UID
+		e.Published = now.Add(-time.Duration(i) * time.Minute)
+		expect = append(expect, e.ID)
+
+		if err := entries.Create(context.Background(), *user, *e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{Entries: entries})
+
+	first, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Paging.After == "" {
+		t.Fatal("expect non empty after cursor, got empty")
+	}
+
+	second, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{
+		After: first.Paging.After,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.Paging.After != "" {
+		t.Errorf("expect empty after cursor on last page, got '%s'", second.Paging.After)
+	}
+
+	actual := make([]string, 0, 30)
+	for _, e := range append(first.Items, second.Items...) {
+		actual = append(actual, e.ID)
+	}
+
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	// new entry must not shift already returned pages
+	fresh := domain.TestEntry(t)
+	fresh.Channel = channel.UID
+	fresh.Published = now.Add(time.Minute)
+
+	if err = entries.Create(context.Background(), *user, *fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	newer, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{
+		Before: first.Paging.Before,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(newer.Items) != 1 || newer.Items[0].ID != fresh.ID {
+		t.Errorf("expect only %s entry, got %+v", fresh.ID, newer.Items)
+	}
+
+	again, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{
+		After: first.Paging.After,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(second, again); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestTimelineUseCase_Fetch_Cursor(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+
+	if _, err := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	}).
+		Fetch(context.Background(), *user, "home", domain.Paging{After: "!invalid"}); err == nil {
+		t.Error("expect error for invalid cursor, got nil")
+	}
+}
+
+func TestTimelineUseCase_Create(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{Entries: entries})
+	entry := domain.TestEntry(t)
+
+	for i, expect := range []int{1, 0} {
+		actual, err := timelines.Create(context.Background(), *user, channel.UID, *entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(actual) != expect {
+			t.Errorf("#%d: expect %d created entries, got %d", i, expect, len(actual))
+		}
+	}
+
+	result, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 || result[0].UID != entry.UID {
+		t.Errorf("expect single %s entry, got %+v", entry.UID, result)
+	}
+}
+
+func TestTimelineUseCase_Create_Sanitized(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	entry := domain.TestEntry(t)
+	entry.URL = "https://example.com/posts/hello"
+	entry.Content = &domain.Content{
+		Text: "Hello",
+		HTML: `<p onclick="steal()">Hello</p><script>steal()</script><a href="/about">about</a>`,
+	}
+
+	if _, err := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{Entries: entries}).
+		Create(context.Background(), *user, channel.UID, *entry); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `<p>Hello</p><a href="https://example.com/about">about</a>`
+	if len(result) != 1 || result[0].Content == nil || result[0].Content.HTML != expect {
+		t.Errorf("expect single entry with %s content, got %+v", expect, result)
+	}
+}
+
+func TestTimelineUseCase_Fetch_Muted(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	mutes := authormemoryrepo.NewMemoryAuthorRepository()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: entries,
+		Mutes:   mutes,
+	})
+	visible, muted := domain.TestEntry(t), domain.TestEntry(t)
+
+	if _, err := timelines.Create(context.Background(), *user, channel.UID, *visible, *muted); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mutes.Create(context.Background(), *user, common.ChannelGlobal, *muted.Author); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Items) != 1 || result.Items[0].UID != visible.UID {
+		t.Errorf("expect single %s entry, got %+v", visible.UID, result.Items)
+	}
+
+	// muted entries are still stored
+	stored, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored) != 2 {
+		t.Errorf("expect %d stored entries, got %d", 2, len(stored))
+	}
+}
+
+func TestTimelineUseCase_Create_Blocked(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	blocks := authormemoryrepo.NewMemoryAuthorRepository()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+		Blocks:  blocks,
+	})
+	entry := domain.TestEntry(t)
+
+	if err := blocks.Create(context.Background(), *user, channel.UID, *entry.Author); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := timelines.Create(context.Background(), *user, channel.UID, *entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actual) != 0 {
+		t.Errorf("expect no entries of blocked user, got %+v", actual)
+	}
+}
+
+func TestTimelineUseCase_MarkRead(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+	now := time.Now().UTC()
+
+	entries := make([]domain.Entry, 5)
+	for i := range entries {
+		entries[i] = *domain.TestEntry(t)
+		entries[i].Published = now.Add(-time.Duration(i) * time.Minute)
+	}
+
+	created, err := timelines.Create(context.Background(), *user, channel.UID, entries...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		mark   func() error
+		expect int
+	}{{
+		name: "entries",
+		mark: func() error {
+			return timelines.MarkRead(context.Background(), *user, channel.UID, created[0].ID, created[1].ID, "unknown")
+		},
+		expect: 3,
+	}, {
+		name: "unread",
+		mark: func() error {
+			return timelines.MarkUnread(context.Background(), *user, channel.UID, created[1].ID)
+		},
+		expect: 4,
+	}, {
+		name: "last_read_entry",
+		mark: func() error {
+			return timelines.MarkReadBefore(context.Background(), *user, channel.UID, created[2].ID)
+		},
+		expect: 1,
+	}, {
+		name: "other channel",
+		mark: func() error {
+			return timelines.MarkRead(context.Background(), *user, "other", created[1].ID)
+		},
+		expect: 1,
+	}} {
+		if err := tc.mark(); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		actual, err := timelines.Unread(context.Background(), *user, channel.UID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != tc.expect {
+			t.Errorf("%s: expect %d unread entries, got %d", tc.name, tc.expect, actual)
+		}
+	}
+
+	result, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range result.Items {
+		if expect := e.ID != created[1].ID; e.IsRead != expect {
+			t.Errorf("expect %t read state of %s entry, got %t", expect, e.ID, e.IsRead)
+		}
+	}
+}
+
+func TestTimelineUseCase_Remove(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+	removed, kept := domain.TestEntry(t), domain.TestEntry(t)
+
+	created, err := timelines.Create(context.Background(), *user, channel.UID, *removed, *kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = timelines.Remove(context.Background(), *user, channel.UID, created[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same source delivers removed entry again on the next poll
+	again, err := timelines.Create(context.Background(), *user, channel.UID, *removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(again) != 0 {
+		t.Errorf("expect removed entry is not delivered again, got %+v", again)
+	}
+
+	// and edited removed entry must not be restored too
+	edited := *removed
+	edited.Name = "edited"
+
+	if again, err = timelines.Create(context.Background(), *user, channel.UID, edited); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(again) != 0 {
+		t.Errorf("expect edited removed entry is not delivered again, got %+v", again)
+	}
+
+	result, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Items) != 1 || result.Items[0].UID != kept.UID {
+		t.Errorf("expect single %s entry, got %+v", kept.UID, result.Items)
+	}
+}
+
+func TestTimelineUseCase_Remove_WithoutIdentity(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+	})
+
+	// h-entry without u-uid and u-url is recognized only by its content
+	entry := domain.TestEntry(t)
+	entry.UID, entry.URL = "", ""
+
+	created, err := timelines.Create(context.Background(), *user, channel.UID, *entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = timelines.Remove(context.Background(), *user, channel.UID, created[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := timelines.Create(context.Background(), *user, channel.UID, *entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(again) != 0 {
+		t.Errorf("expect removed entry is not delivered again, got %+v", again)
+	}
+}
+
+func TestTimelineUseCase_Create_Events(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	events := eventmemory.NewMemoryEventBus()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+		Events:  events,
+	})
+
+	stream, unsubscribe := events.Subscribe(*user)
+	defer unsubscribe()
+
+	created, err := timelines.Create(context.Background(), *user, channel.UID, *domain.TestEntry(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = timelines.MarkRead(context.Background(), *user, channel.UID, created[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []domain.EventType{domain.EventTypeNewItem, domain.EventTypeMarkRead} {
+		select {
+		case e := <-stream:
+			if e.Type != expect || e.Channel.UID != channel.UID {
+				t.Errorf("want %s event in %s channel, got %s in %s", expect, channel.UID, e.Type,
+					e.Channel.UID)
+			}
+		default:
+			t.Fatalf("want %s event, got nothing", expect)
+		}
+	}
+}
+
+func TestTimelineUseCase_Create_EventsMuted(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	events := eventmemory.NewMemoryEventBus()
+	mutes := authormemoryrepo.NewMemoryAuthorRepository()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+		Mutes:   mutes,
+		Events:  events,
+	})
+	first, muted, second := domain.TestEntry(t), domain.TestEntry(t), domain.TestEntry(t)
+
+	if err := mutes.Create(context.Background(), *user, channel.UID, *muted.Author); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, unsubscribe := events.Subscribe(*user)
+	defer unsubscribe()
+
+	if _, err := timelines.Create(context.Background(), *user, channel.UID, *first, *muted, *second); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []*domain.Entry{first, second} {
+		select {
+		case e := <-stream:
+			if e.Entry == nil || e.Entry.UID != expect.UID {
+				t.Errorf("want event about %s entry, got %+v", expect.UID, e.Entry)
+			}
+		default:
+			t.Fatalf("want event about %s entry, got nothing", expect.UID)
+		}
+	}
+
+	select {
+	case e := <-stream:
+		t.Errorf("want no more events, got %+v", e)
+	default:
+	}
+}
+
+func TestTimelineUseCase_Create_Edited(t *testing.T) {
+	t.Parallel()
+
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{Entries: entries})
+	entry := domain.TestEntry(t)
+
+	created, err := timelines.Create(context.Background(), *user, channel.UID, *entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = timelines.MarkRead(context.Background(), *user, channel.UID, created[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := *entry
+	edited.Content = &domain.Content{Text: "edited"}
+
+	again, err := timelines.Create(context.Background(), *user, channel.UID, edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(again) != 0 {
+		t.Errorf("expect edited entry is not created again, got %+v", again)
+	}
+
+	result, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("expect single entry, got %+v", result)
+	}
+
+	if result[0].ID != created[0].ID {
+		t.Errorf("expect %s ID, got %s", created[0].ID, result[0].ID)
+	}
+
+	if !result[0].IsRead {
+		t.Error("expect edited entry keeps read state")
+	}
+
+	if result[0].Content == nil || result[0].Content.Text != "edited" {
+		t.Errorf("expect updated content, got %+v", result[0].Content)
+	}
+}
+
+func TestTimelineUseCase_Create_Duplicates(t *testing.T) {
+	t.Parallel()
+
+	entry := domain.TestEntry(t)
+	entry.URL = "https://example.com/post/1"
+
+	for name, tc := range map[string]struct {
+		stored, delivered func(e *domain.Entry)
+	}{
+		"uid": {
+			stored:    func(e *domain.Entry) { e.URL = "https://example.com/post/2" },
+			delivered: func(e *domain.Entry) {},
+		},
+		"url": {
+			stored: func(e *domain.Entry) { e.UID = "" },
+			delivered: func(e *domain.Entry) {
+				e.UID = ""
+				e.URL = "HTTPS://Example.com:443/post/1?utm_source=feed#comments"
+			},
+		},
+		"content": {
+			stored:    func(e *domain.Entry) { e.UID, e.URL = "", "" },
+			delivered: func(e *domain.Entry) { e.UID, e.URL = "", "" },
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			user := domain.TestUser(t)
+			channel := domain.TestChannel(t)
+			entries := timelinememoryrepo.NewMemoryTimelineRepository()
+			timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{Entries: entries})
+			stored, delivered := *entry, *entry
+
+			tc.stored(&stored)
+			tc.delivered(&delivered)
+
+			if _, err := timelines.Create(context.Background(), *user, channel.UID, stored); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := timelines.Create(context.Background(), *user, channel.UID, delivered)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(actual) != 0 {
+				t.Errorf("expect duplicate is not created, got %+v", actual)
+			}
+
+			result, err := entries.Fetch(context.Background(), *user, channel.UID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != 1 {
+				t.Errorf("expect single entry, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestTimelineUseCase_Compact(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		policy domain.Retention
+		expect []string
+	}{
+		"empty": {policy: domain.Retention{}, expect: []string{"unread", "day", "week", "month"}},
+		"count": {policy: domain.Retention{MaxCount: 2}, expect: []string{"unread", "day"}},
+		"age":   {policy: domain.Retention{MaxAge: 72 * time.Hour}, expect: []string{"unread", "day"}},
+		"keep unread": {
+			policy: domain.Retention{MaxCount: 1, KeepUnread: true},
+			expect: []string{"unread", "month"},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			user := domain.TestUser(t)
+			channel := domain.TestChannel(t)
+			timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+				Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+			})
+
+			entries := make([]domain.Entry, 0)
+			read := make(map[string]bool)
+
+			for _, src := range []struct {
+				uid  string
+				age  time.Duration
+				read bool
+			}{
+				{uid: "unread", age: 0},
+				{uid: "day", age: 24 * time.Hour, read: true},
+				{uid: "week", age: 7 * 24 * time.Hour, read: true},
+				{uid: "month", age: 30 * 24 * time.Hour},
+			} {
+				e := domain.TestEntry(t)
+				e.UID = src.uid
+				e.Published = now.Add(-src.age)
+				entries = append(entries, *e)
+				read[src.uid] = src.read
+			}
+
+			created, err := timelines.Create(context.Background(), *user, channel.UID, entries...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := make([]string, 0)
+			for i := range created {
+				if read[created[i].UID] {
+					ids = append(ids, created[i].ID)
+				}
+			}
+
+			if err = timelines.MarkRead(context.Background(), *user, channel.UID, ids...); err != nil {
+				t.Fatal(err)
+			}
+
+			count, err := timelines.Compact(context.Background(), *user, channel.UID, tc.policy, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count != len(entries)-len(tc.expect) {
+				t.Errorf("expect %d compacted entries, got %d", len(entries)-len(tc.expect), count)
+			}
+
+			result, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := make([]string, 0, len(result.Items))
+			for i := range result.Items {
+				actual = append(actual, result.Items[i].UID)
+			}
+
+			if diff := cmp.Diff(tc.expect, actual); diff != "" {
+				t.Error(diff)
+			}
+
+			// compacted entries are not delivered again
+			if again, _ := timelines.Create(context.Background(), *user, channel.UID,
+				entries...); len(again) != 0 {
+				t.Errorf("expect compacted entries are not delivered again, got %+v", again)
+			}
+		})
+	}
+}
+
+func TestTimelineUseCase_Compact_Muted(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	mutes := authormemoryrepo.NewMemoryAuthorRepository()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{
+		Entries: timelinememoryrepo.NewMemoryTimelineRepository(),
+		Mutes:   mutes,
+	})
+	visible, muted := domain.TestEntry(t), domain.TestEntry(t)
+	visible.Published, muted.Published = now.Add(-time.Hour), now
+
+	if _, err := timelines.Create(context.Background(), *user, channel.UID, *visible, *muted); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mutes.Create(context.Background(), *user, common.ChannelGlobal, *muted.Author); err != nil {
+		t.Fatal(err)
+	}
+
+	// newer muted entry does not push the visible one out
+	count, err := timelines.Compact(context.Background(), *user, channel.UID, domain.Retention{MaxCount: 1}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Errorf("expect no compacted entries, got %d", count)
+	}
+
+	result, err := timelines.Fetch(context.Background(), *user, channel.UID, domain.Paging{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Items) != 1 || result.Items[0].UID != visible.UID {
+		t.Errorf("expect single %s entry, got %+v", visible.UID, result.Items)
+	}
+}
+
+func TestTimelineUseCase_Compact_Tombstones(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+	user := domain.TestUser(t)
+	channel := domain.TestChannel(t)
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	timelines := ucase.NewTimelineUseCase(ucase.NewTimelineUseCaseOptions{Entries: entries})
+
+	// source delivered more entries than its feed contains at once
+	delivered := make([]domain.Entry, 0)
+
+	for i := 0; i < 101; i++ {
+		e := domain.TestEntry(t)
+		e.Source = "https://example.com/feed"
+		e.Published = now.Add(-time.Duration(i) * time.Minute)
+		delivered = append(delivered, *e)
+	}
+
+	created, err := timelines.Create(context.Background(), *user, channel.UID, delivered...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make(map[string]string, len(created))
+	for i := range created {
+		ids[created[i].UID] = created[i].ID
+	}
+
+	newest, oldest := delivered[0], delivered[len(delivered)-1]
+
+	if err = timelines.Remove(context.Background(), *user, channel.UID, ids[newest.UID],
+		ids[oldest.UID]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = timelines.Compact(context.Background(), *user, channel.UID, domain.Retention{MaxAge: 24 * time.Hour},
+		now); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := entries.Fetch(context.Background(), *user, channel.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored) != len(delivered)-1 {
+		t.Errorf("expect %d stored entries after pruning, got %d", len(delivered)-1, len(stored))
+	}
+
+	// tombstone in the feed window still blocks delivery
+	if again, _ := timelines.Create(context.Background(), *user, channel.UID, newest); len(again) != 0 {
+		t.Errorf("expect removed newest entry is not delivered again, got %+v", again)
+	}
+}
@@ -0,0 +1,30 @@
+// Package user describes the registry of users who have signed in, so
+// background jobs and public endpoints know them without any stored data. It
+// also keeps settings of the notifications channel, which every user has
+// without storing it.
+package user
+
+import (
+	"context"
+	"errors"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+)
+
+type Repository interface {
+	Create(ctx context.Context, user domain.User) error
+
+	// Fetch returns all known users ordered by their URLs. Only URLs of
+	// users are kept.
+	Fetch(ctx context.Context) ([]domain.User, error)
+
+	// GetRetention returns own retention policy of notifications channel
+	// of user, or nil if channel follows the default one.
+	GetRetention(ctx context.Context, user domain.User) (*domain.Retention, error)
+
+	// SetRetention changes retention policy of notifications channel of
+	// user, registering unknown user. Nil policy restores the default one.
+	SetRetention(ctx context.Context, user domain.User, policy *domain.Retention) error
+}
+
+var ErrExist = errors.New("user already known")
@@ -0,0 +1,167 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+
+	database "source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/user"
+)
+
+type (
+	boltUserRepository struct {
+		db *bolt.DB
+	}
+
+	// Retention is a stored retention policy of notifications channel.
+	Retention struct {
+		MaxAge     time.Duration `json:"max_age,omitempty"`
+		MaxCount   int           `json:"max_count,omitempty"`
+		KeepUnread bool          `json:"keep_unread,omitempty"`
+	}
+)
+
+// keyRetention is a key of notifications retention policy in the bucket of
+// user.
+var keyRetention = []byte("notifications_retention")
+
+// NewBoltUserRepository creates users registry in database opened by
+// bolt.Open. Users are the buckets which keep all their data, so users stored
+// before the registry are known as well.
+func NewBoltUserRepository(db *bolt.DB) user.Repository {
+	return &boltUserRepository{
+		db: db,
+	}
+}
+
+func (repo *boltUserRepository) Create(ctx context.Context, u domain.User) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		users, err := database.Users(tx)
+		if err != nil {
+			return err
+		}
+
+		if _, err = users.CreateBucket([]byte(u.String())); err != nil {
+			if errors.Is(err, bolt.ErrBucketExists) {
+				return user.ErrExist
+			}
+
+			return fmt.Errorf("cannot create user bucket: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (repo *boltUserRepository) Fetch(ctx context.Context) ([]domain.User, error) {
+	out := make([]domain.User, 0)
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		users, err := database.Users(tx)
+		if err != nil {
+			return err
+		}
+
+		return users.ForEach(func(key, value []byte) error {
+			// users bucket contains only nested buckets
+			if value != nil {
+				return nil
+			}
+
+			me, err := url.Parse(string(key))
+			if err != nil {
+				return fmt.Errorf("cannot parse user: %w", err)
+			}
+
+			out = append(out, domain.User{URL: me})
+
+			return nil
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("cannot fetch users: %w", err)
+	}
+
+	return out, nil
+}
+
+func (repo *boltUserRepository) GetRetention(ctx context.Context, u domain.User) (*domain.Retention, error) {
+	var out *domain.Retention
+
+	if err := repo.db.View(func(tx *bolt.Tx) error {
+		users, err := database.Users(tx)
+		if err != nil {
+			return err
+		}
+
+		bucket := users.Bucket([]byte(u.String()))
+		if bucket == nil {
+			return nil
+		}
+
+		value := bucket.Get(keyRetention)
+		if value == nil {
+			return nil
+		}
+
+		policy := new(Retention)
+		if err = json.Unmarshal(value, policy); err != nil {
+			return fmt.Errorf("cannot decode retention policy: %w", err)
+		}
+
+		out = policy.Populate()
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("cannot get notifications retention policy: %w", err)
+	}
+
+	return out, nil
+}
+
+func (repo *boltUserRepository) SetRetention(ctx context.Context, u domain.User, policy *domain.Retention) error {
+	return repo.db.Update(func(tx *bolt.Tx) error {
+		users, err := database.Users(tx)
+		if err != nil {
+			return err
+		}
+
+		bucket, err := users.CreateBucketIfNotExists([]byte(u.String()))
+		if err != nil {
+			return fmt.Errorf("cannot create user bucket: %w", err)
+		}
+
+		if policy == nil {
+			return bucket.Delete(keyRetention)
+		}
+
+		value, err := json.Marshal(NewRetention(*policy))
+		if err != nil {
+			return fmt.Errorf("cannot encode retention policy: %w", err)
+		}
+
+		return bucket.Put(keyRetention, value)
+	})
+}
+
+func NewRetention(r domain.Retention) *Retention {
+	return &Retention{
+		MaxAge:     r.MaxAge,
+		MaxCount:   r.MaxCount,
+		KeepUnread: r.KeepUnread,
+	}
+}
+
+func (r Retention) Populate() *domain.Retention {
+	return &domain.Retention{
+		MaxAge:     r.MaxAge,
+		MaxCount:   r.MaxCount,
+		KeepUnread: r.KeepUnread,
+	}
+}
@@ -0,0 +1,58 @@
+package bolt_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	bbolt "go.etcd.io/bbolt"
+
+	channelboltrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/bolt"
+	"source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	repository "source.toby3d.me/toby3d/sub/internal/user/repository/bolt"
+	"source.toby3d.me/toby3d/sub/internal/user/usertest"
+)
+
+func TestBoltUserRepository(t *testing.T) {
+	t.Parallel()
+
+	usertest.TestRepository(t, func(tb testing.TB) user.Repository {
+		return repository.NewBoltUserRepository(open(tb))
+	})
+}
+
+func TestBoltUserRepository_Stored(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := open(t)
+
+	if err := channelboltrepo.NewBoltChannelRepository(db).Create(ctx, *domain.TestUser(t),
+		*domain.TestChannel(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := repository.NewBoltUserRepository(db).Fetch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users) != 1 || users[0].String() != domain.TestUser(t).String() {
+		t.Errorf("want user with stored channel, got %+v", users)
+	}
+}
+
+func open(tb testing.TB) *bbolt.DB {
+	tb.Helper()
+
+	db, err := bolt.Open(filepath.Join(tb.TempDir(), "sub.db"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
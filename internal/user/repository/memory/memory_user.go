@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/user"
+)
+
+type memoryUserRepository struct {
+	mutex    *sync.RWMutex
+	users    map[string]domain.User
+	policies map[string]domain.Retention
+}
+
+func NewMemoryUserRepository() user.Repository {
+	return &memoryUserRepository{
+		mutex:    new(sync.RWMutex),
+		users:    make(map[string]domain.User),
+		policies: make(map[string]domain.Retention),
+	}
+}
+
+func (repo *memoryUserRepository) Create(ctx context.Context, u domain.User) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.users[u.String()]; ok {
+		return user.ErrExist
+	}
+
+	repo.users[u.String()] = domain.User{URL: u.URL}
+
+	return nil
+}
+
+func (repo *memoryUserRepository) Fetch(ctx context.Context) ([]domain.User, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	out := make([]domain.User, 0, len(repo.users))
+	for _, u := range repo.users {
+		out = append(out, u)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].String() < out[j].String() })
+
+	return out, nil
+}
+
+func (repo *memoryUserRepository) GetRetention(ctx context.Context, u domain.User) (*domain.Retention, error) {
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	policy, ok := repo.policies[u.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	return &policy, nil
+}
+
+func (repo *memoryUserRepository) SetRetention(ctx context.Context, u domain.User, policy *domain.Retention) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, ok := repo.users[u.String()]; !ok {
+		repo.users[u.String()] = domain.User{URL: u.URL}
+	}
+
+	if policy == nil {
+		delete(repo.policies, u.String())
+
+		return nil
+	}
+
+	repo.policies[u.String()] = *policy
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/user"
+	repository "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/user/usertest"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	t.Parallel()
+
+	usertest.TestRepository(t, func(tb testing.TB) user.Repository {
+		return repository.NewMemoryUserRepository()
+	})
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/user"
+)
+
+type (
+	postgresUserRepository struct {
+		db *sqlx.DB
+	}
+
+	// User is a row of users table.
+	User struct {
+		Me string `db:"me"`
+	}
+
+	// Retention is a row of users table with retention policy of
+	// notifications channel.
+	Retention struct {
+		Me                  string        `db:"me"`
+		RetentionMaxAge     sql.NullInt64 `db:"retention_max_age"`
+		RetentionMaxCount   sql.NullInt64 `db:"retention_max_count"`
+		RetentionKeepUnread sql.NullBool  `db:"retention_keep_unread"`
+	}
+)
+
+const (
+	queryFetch        string = "SELECT me FROM users ORDER BY me"
+	queryCreate       string = "INSERT INTO users (me) VALUES (:me) ON CONFLICT (me) DO NOTHING"
+	queryGetRetention string = `SELECT me, retention_max_age, retention_max_count, retention_keep_unread
+		FROM users WHERE me = $1`
+	querySetRetention string = `INSERT INTO users (me, retention_max_age, retention_max_count, retention_keep_unread)
+		VALUES (:me, :retention_max_age, :retention_max_count, :retention_keep_unread)
+		ON CONFLICT (me) DO UPDATE SET retention_max_age = excluded.retention_max_age,
+			retention_max_count = excluded.retention_max_count,
+			retention_keep_unread = excluded.retention_keep_unread`
+)
+
+// NewPostgresUserRepository creates users registry in database opened by
+// postgres.Open.
+func NewPostgresUserRepository(db *sqlx.DB) user.Repository {
+	return &postgresUserRepository{
+		db: db,
+	}
+}
+
+func (repo *postgresUserRepository) Create(ctx context.Context, u domain.User) error {
+	result, err := repo.db.NamedExecContext(ctx, queryCreate, NewUser(u))
+	if err != nil {
+		return fmt.Errorf("cannot create user: %w", err)
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return user.ErrExist
+	}
+
+	return nil
+}
+
+func (repo *postgresUserRepository) Fetch(ctx context.Context) ([]domain.User, error) {
+	rows := make([]User, 0)
+	if err := repo.db.SelectContext(ctx, &rows, queryFetch); err != nil {
+		return nil, fmt.Errorf("cannot fetch users: %w", err)
+	}
+
+	out := make([]domain.User, 0, len(rows))
+
+	for i := range rows {
+		u, err := rows[i].Populate()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, *u)
+	}
+
+	return out, nil
+}
+
+func (repo *postgresUserRepository) GetRetention(ctx context.Context, u domain.User) (*domain.Retention, error) {
+	row := new(Retention)
+	if err := repo.db.GetContext(ctx, row, queryGetRetention, u.String()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cannot get notifications retention policy: %w", err)
+	}
+
+	return row.Populate(), nil
+}
+
+func (repo *postgresUserRepository) SetRetention(ctx context.Context, u domain.User, policy *domain.Retention) error {
+	if _, err := repo.db.NamedExecContext(ctx, querySetRetention, NewRetention(u, policy)); err != nil {
+		return fmt.Errorf("cannot set notifications retention policy: %w", err)
+	}
+
+	return nil
+}
+
+func NewUser(u domain.User) *User {
+	return &User{
+		Me: u.String(),
+	}
+}
+
+func (u User) Populate() (*domain.User, error) {
+	me, err := url.Parse(u.Me)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse user: %w", err)
+	}
+
+	return &domain.User{URL: me}, nil
+}
+
+func NewRetention(u domain.User, policy *domain.Retention) *Retention {
+	out := &Retention{
+		Me: u.String(),
+	}
+
+	if policy != nil {
+		out.RetentionMaxAge = sql.NullInt64{Int64: int64(policy.MaxAge), Valid: true}
+		out.RetentionMaxCount = sql.NullInt64{Int64: int64(policy.MaxCount), Valid: true}
+		out.RetentionKeepUnread = sql.NullBool{Bool: policy.KeepUnread, Valid: true}
+	}
+
+	return out
+}
+
+func (r Retention) Populate() *domain.Retention {
+	// policy columns are stored together, so any of them marks own policy
+	if !r.RetentionMaxAge.Valid {
+		return nil
+	}
+
+	return &domain.Retention{
+		MaxAge:     time.Duration(r.RetentionMaxAge.Int64),
+		MaxCount:   int(r.RetentionMaxCount.Int64),
+		KeepUnread: r.RetentionKeepUnread.Bool,
+	}
+}
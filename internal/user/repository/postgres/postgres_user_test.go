@@ -0,0 +1,18 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/database/postgres"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	repository "source.toby3d.me/toby3d/sub/internal/user/repository/postgres"
+	"source.toby3d.me/toby3d/sub/internal/user/usertest"
+)
+
+func TestPostgresUserRepository(t *testing.T) {
+	t.Parallel()
+
+	usertest.TestRepository(t, func(tb testing.TB) user.Repository {
+		return repository.NewPostgresUserRepository(postgres.TestDatabase(tb))
+	})
+}
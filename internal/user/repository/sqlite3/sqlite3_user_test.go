@@ -0,0 +1,28 @@
+package sqlite3_test
+
+import (
+	"context"
+	"testing"
+
+	"source.toby3d.me/toby3d/sub/internal/database/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	repository "source.toby3d.me/toby3d/sub/internal/user/repository/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/user/usertest"
+)
+
+func TestSQLite3UserRepository(t *testing.T) {
+	t.Parallel()
+
+	usertest.TestRepository(t, func(tb testing.TB) user.Repository {
+		tb.Helper()
+
+		db, err := sqlite3.Open(context.Background(), ":memory:")
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		tb.Cleanup(func() { _ = db.Close() })
+
+		return repository.NewSQLite3UserRepository(db)
+	})
+}
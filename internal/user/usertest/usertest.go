@@ -0,0 +1,124 @@
+// Package usertest contains conformance tests which every implementation of
+// user.Repository must pass.
+package usertest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/user"
+)
+
+// TestRepository runs conformance tests against the empty repository returned
+// by newRepository.
+func TestRepository(t *testing.T, newRepository func(tb testing.TB) user.Repository) {
+	t.Helper()
+
+	t.Run("Create", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		repo := newRepository(t)
+
+		if err := repo.Create(ctx, *domain.TestUser(t)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.Create(ctx, *domain.TestUser(t)); !errors.Is(err, user.ErrExist) {
+			t.Errorf("want %v for duplicate, got %v", user.ErrExist, err)
+		}
+	})
+
+	t.Run("Fetch", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		repo := newRepository(t)
+
+		users, err := repo.Fetch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(users) != 0 {
+			t.Errorf("want no users in empty repository, got %+v", users)
+		}
+
+		expect := []string{"https://alice.example.com/", "https://bob.example.com/", "https://example.com/carol/"}
+
+		for _, i := range []int{2, 0, 1} {
+			me, _ := url.Parse(expect[i])
+			if err = repo.Create(ctx, domain.User{URL: me, Scopes: []domain.Scope{domain.ScopeRead}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if users, err = repo.Fetch(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(users) != len(expect) {
+			t.Fatalf("want %d users, got %+v", len(expect), users)
+		}
+
+		for i := range users {
+			if users[i].String() != expect[i] {
+				t.Errorf("want %s at %d, got %s", expect[i], i, users[i])
+			}
+
+			if len(users[i].Scopes) != 0 {
+				t.Errorf("want no scopes of stored user, got %v", users[i].Scopes)
+			}
+		}
+	})
+	t.Run("Retention", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		repo := newRepository(t)
+		u := domain.TestUser(t)
+
+		policy, err := repo.GetRetention(ctx, *u)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if policy != nil {
+			t.Errorf("want no policy of unknown user, got %+v", policy)
+		}
+
+		expect := &domain.Retention{MaxAge: time.Hour, MaxCount: 10, KeepUnread: true}
+		if err = repo.SetRetention(ctx, *u, expect); err != nil {
+			t.Fatal(err)
+		}
+
+		if policy, err = repo.GetRetention(ctx, *u); err != nil {
+			t.Fatal(err)
+		}
+
+		if policy == nil || *policy != *expect {
+			t.Errorf("want %+v policy, got %+v", expect, policy)
+		}
+
+		// policy registers the user
+		if err = repo.Create(ctx, *u); !errors.Is(err, user.ErrExist) {
+			t.Errorf("want %v for user with policy, got %v", user.ErrExist, err)
+		}
+
+		if err = repo.SetRetention(ctx, *u, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if policy, err = repo.GetRetention(ctx, *u); err != nil {
+			t.Fatal(err)
+		}
+
+		if policy != nil {
+			t.Errorf("want default policy after reset, got %+v", policy)
+		}
+	})
+}
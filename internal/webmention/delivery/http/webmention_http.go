@@ -0,0 +1,66 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	microsubhttpdelivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+	"source.toby3d.me/toby3d/sub/internal/webmention"
+)
+
+// Handler receives Webmentions of users sites. Requests are only checked and
+// queued, sources are verified later.
+//
+// Queue is kept in memory: accepted but not yet verified webmentions are lost
+// on restart, senders are expected to resend them.
+type Handler struct {
+	webmentions webmention.UseCase
+}
+
+// maxBodySize limits size of request body.
+const maxBodySize int64 = 1 << 16
+
+func NewHandler(ucase webmention.UseCase) *Handler {
+	return &Handler{
+		webmentions: ucase,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		microsubhttpdelivery.WriteError(w, microsubhttpdelivery.NewMethodError(r.Method))
+
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := r.ParseForm(); err != nil {
+		microsubhttpdelivery.WriteError(w, fmt.Errorf("%w: %s", microsubhttpdelivery.ErrBody, err))
+
+		return
+	}
+
+	source, sourceErr := url.Parse(r.PostFormValue("source"))
+	target, targetErr := url.Parse(r.PostFormValue("target"))
+
+	if sourceErr != nil || targetErr != nil {
+		microsubhttpdelivery.WriteError(w, webmention.ErrRequest)
+
+		return
+	}
+
+	if err := h.webmentions.Receive(r.Context(), source, target); err != nil {
+		if errors.Is(err, webmention.ErrQueue) {
+			w.Header().Set("Retry-After", "60")
+		}
+
+		microsubhttpdelivery.WriteError(w, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,114 @@
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+
+	"source.toby3d.me/toby3d/sub/internal/common"
+	microsubhttpdelivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+	"source.toby3d.me/toby3d/sub/internal/webmention"
+	delivery "source.toby3d.me/toby3d/sub/internal/webmention/delivery/http"
+)
+
+// stubUseCase returns err for every received webmention.
+type stubUseCase struct {
+	err error
+}
+
+func (ucase stubUseCase) Receive(ctx context.Context, source, target *url.URL) error {
+	return ucase.err
+}
+
+func (ucase stubUseCase) Verify(ctx context.Context, source, target *url.URL) error {
+	return ucase.err
+}
+
+func (ucase stubUseCase) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		method       string
+		err          error
+		expectCode   string
+		expectStatus int
+	}{
+		"received": {method: http.MethodPost, expectStatus: http.StatusAccepted},
+		"method": {
+			method: http.MethodGet, expectStatus: http.StatusMethodNotAllowed,
+			expectCode: microsubhttpdelivery.ErrorCodeInvalidRequest,
+		},
+		"request": {
+			method: http.MethodPost, err: webmention.ErrRequest, expectStatus: http.StatusBadRequest,
+			expectCode: microsubhttpdelivery.ErrorCodeInvalidRequest,
+		},
+		"wrapped": {
+			method: http.MethodPost, err: fmt.Errorf("wrap: %w", webmention.ErrTarget),
+			expectStatus: http.StatusBadRequest, expectCode: microsubhttpdelivery.ErrorCodeInvalidRequest,
+		},
+		"queue": {
+			method: http.MethodPost, err: webmention.ErrQueue, expectStatus: http.StatusServiceUnavailable,
+			expectCode: microsubhttpdelivery.ErrorCodeTemporarilyUnavailable,
+		},
+		"internals": {
+			method: http.MethodPost, err: context.DeadlineExceeded,
+			expectStatus: http.StatusInternalServerError, expectCode: microsubhttpdelivery.ErrorCodeServerError,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			body := make(url.Values)
+			body.Set("source", "https://alice.example.com/like")
+			body.Set("target", "https://user.example.com/posts/1")
+
+			req := httptest.NewRequest(tc.method, "https://example.com/webmention",
+				strings.NewReader(body.Encode()))
+			req.Header.Set(common.HeaderContentType, common.MIMEApplicationForm)
+
+			w := httptest.NewRecorder()
+			delivery.NewHandler(stubUseCase{err: tc.err}).ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tc.expectStatus {
+				t.Errorf("expect %d, got %d", tc.expectStatus, resp.StatusCode)
+			}
+
+			if tc.expectStatus == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") == "" {
+				t.Error("expect Retry-After header for queue overflow")
+			}
+
+			if tc.expectCode == "" {
+				return
+			}
+
+			actual := new(microsubhttpdelivery.ResponseError)
+			if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+				t.Fatal(err)
+			}
+
+			if actual.Error != tc.expectCode {
+				t.Errorf("expect %s error code, got %s", tc.expectCode, actual.Error)
+			}
+
+			if tc.err != nil && tc.expectStatus == http.StatusInternalServerError &&
+				actual.ErrorDescription == tc.err.Error() {
+				t.Error("expect hidden details of internal error")
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package webmention
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+type UseCase interface {
+	// Receive checks that target belongs to the site of one of users and
+	// queues webmention for verification, so sender does not wait for
+	// fetching of source.
+	Receive(ctx context.Context, source, target *url.URL) error
+
+	// Verify verifies that source links to target on the site of one of
+	// users and delivers source post into notifications channel of this
+	// user. Source which no longer links to target removes previously
+	// delivered notification.
+	Verify(ctx context.Context, source, target *url.URL) error
+
+	// Run verifies queued webmentions until ctx is done.
+	Run(ctx context.Context) error
+}
+
+var (
+	ErrRequest = errors.New("source and target must be different absolute http or https URLs")
+	ErrTarget  = errors.New("target is not supported by this receiver")
+	ErrQueue   = errors.New("too many webmentions are waiting for verification")
+	ErrSource  = errors.New("cannot fetch source")
+	ErrNoLink  = errors.New("source does not link to target")
+)
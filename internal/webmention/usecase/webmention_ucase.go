@@ -0,0 +1,374 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/feed/mf2"
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	"source.toby3d.me/toby3d/sub/internal/webmention"
+)
+
+type (
+	webmentionUseCase struct {
+		client    *http.Client
+		users     user.Repository
+		entries   timeline.Repository
+		timelines timeline.UseCase
+		logger    *log.Logger
+		queue     chan mention
+	}
+
+	NewWebmentionUseCaseOptions struct {
+		Client    *http.Client
+		Users     user.Repository
+		Entries   timeline.Repository
+		Timelines timeline.UseCase
+		Logger    *log.Logger
+
+		// QueueSize limits number of webmentions waiting for
+		// verification. DefaultQueueSize is used if zero.
+		QueueSize int
+	}
+
+	// mention is a received webmention waiting for verification.
+	mention struct {
+		source *url.URL
+		target *url.URL
+	}
+
+	// document is a downloaded source of webmention.
+	document struct {
+		url         *url.URL
+		contentType string
+		body        []byte
+	}
+)
+
+// DefaultQueueSize is a default number of webmentions waiting for
+// verification.
+const DefaultQueueSize int = 256
+
+// maxBodySize limits size of downloaded sources.
+const maxBodySize int64 = 1 << 20
+
+// linkAttributes contains attributes of HTML elements which link source to
+// target.
+var linkAttributes = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"cite":   true,
+	"poster": true,
+}
+
+func NewWebmentionUseCase(opts NewWebmentionUseCaseOptions) webmention.UseCase {
+	// sources are provided by anyone, so they must not reach private
+	// networks by default
+	if opts.Client == nil {
+		opts.Client = httpclient.New(httpclient.Options{})
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = log.New(io.Discard, "", 0)
+	}
+
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+
+	return &webmentionUseCase{
+		client:    opts.Client,
+		users:     opts.Users,
+		entries:   opts.Entries,
+		timelines: opts.Timelines,
+		logger:    opts.Logger,
+		queue:     make(chan mention, opts.QueueSize),
+	}
+}
+
+func (ucase *webmentionUseCase) Receive(ctx context.Context, source, target *url.URL) error {
+	if !isValid(source, target) {
+		return webmention.ErrRequest
+	}
+
+	if _, err := ucase.owner(ctx, target); err != nil {
+		return err
+	}
+
+	select {
+	case ucase.queue <- mention{source: source, target: target}:
+		return nil
+	default:
+		return webmention.ErrQueue
+	}
+}
+
+func (ucase *webmentionUseCase) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m := <-ucase.queue:
+			if err := ucase.Verify(ctx, m.source, m.target); err != nil {
+				ucase.logger.Printf("cannot verify webmention from %s to %s: %s", m.source, m.target, err)
+			}
+		}
+	}
+}
+
+func (ucase *webmentionUseCase) Verify(ctx context.Context, source, target *url.URL) error {
+	if !isValid(source, target) {
+		return webmention.ErrRequest
+	}
+
+	u, err := ucase.owner(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	doc, err := ucase.fetch(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	// deleted source or source without link removes the mention
+	if doc == nil || !doc.links(target) {
+		removed, err := ucase.remove(ctx, *u, source)
+		if err != nil {
+			return err
+		}
+
+		if doc == nil || removed {
+			return nil
+		}
+
+		return webmention.ErrNoLink
+	}
+
+	if _, err = ucase.timelines.Create(ctx, *u, common.ChannelNotifications, doc.entry(source, target)); err != nil {
+		return fmt.Errorf("cannot deliver webmention: %w", err)
+	}
+
+	return nil
+}
+
+// owner returns user whose site contains target. Site is everything under the
+// path of user URL, so the user with the longest matching path wins on shared
+// hosts.
+func (ucase *webmentionUseCase) owner(ctx context.Context, target *url.URL) (*domain.User, error) {
+	users, err := ucase.users.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch users: %w", err)
+	}
+
+	path := target.Path
+	if path == "" {
+		path = "/"
+	}
+
+	var out *domain.User
+
+	longest := -1
+
+	for i := range users {
+		me := users[i].URL
+		if me == nil || !strings.EqualFold(me.Host, target.Host) {
+			continue
+		}
+
+		// prefix always ends by slash, so '/alice' does not own
+		// '/alicebob'
+		prefix := me.Path
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+
+		if (strings.HasPrefix(path, prefix) || path+"/" == prefix) && len(prefix) > longest {
+			out, longest = &users[i], len(prefix)
+		}
+	}
+
+	if out == nil {
+		return nil, webmention.ErrTarget
+	}
+
+	return out, nil
+}
+
+// fetch downloads source document. Deleted source is returned as nil.
+func (ucase *webmentionUseCase) fetch(ctx context.Context, source *url.URL) (*document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "text/html, */*;q=0.1")
+
+	resp, err := ucase.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", webmention.ErrSource, source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		return nil, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w %s: unexpected status %s", webmention.ErrSource, source, resp.Status)
+	}
+
+	out := &document{
+		url:         resp.Request.URL,
+		contentType: resp.Header.Get("Content-Type"),
+	}
+
+	if out.body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodySize)); err != nil {
+		return nil, fmt.Errorf("%w %s: %s", webmention.ErrSource, source, err)
+	}
+
+	return out, nil
+}
+
+// remove deletes notifications delivered from source and reports whether
+// there was any.
+func (ucase *webmentionUseCase) remove(ctx context.Context, u domain.User, source *url.URL) (bool, error) {
+	entries, err := ucase.entries.Fetch(ctx, u, common.ChannelNotifications)
+	if err != nil {
+		return false, fmt.Errorf("cannot fetch notifications: %w", err)
+	}
+
+	out := false
+
+	for i := range entries {
+		if entries[i].Source != source.String() {
+			continue
+		}
+
+		if err = ucase.entries.Delete(ctx, u, entries[i].ID); err != nil && !errors.Is(err, timeline.ErrNotExist) {
+			return out, fmt.Errorf("cannot delete notification: %w", err)
+		}
+
+		out = true
+	}
+
+	return out, nil
+}
+
+// links reports whether document contains link to target.
+func (doc *document) links(target *url.URL) bool {
+	expect := withoutFragment(target)
+
+	if !(mf2.Format{}).Sniff(doc.contentType, doc.body) {
+		return bytes.Contains(doc.body, []byte(expect))
+	}
+
+	root, err := html.Parse(bytes.NewReader(doc.body))
+	if err != nil {
+		return false
+	}
+
+	var walk func(n *html.Node) bool
+
+	walk = func(n *html.Node) bool {
+		if n.Type == html.ElementNode {
+			for _, a := range n.Attr {
+				if !linkAttributes[a.Key] {
+					continue
+				}
+
+				if u, err := doc.url.Parse(strings.TrimSpace(a.Val)); err == nil && withoutFragment(u) == expect {
+					return true
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if walk(c) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return walk(root)
+}
+
+// entry returns source post as notification entry. Source without
+// microformats2 markup becomes a plain mention.
+func (doc *document) entry(source, target *url.URL) domain.Entry {
+	out := domain.Entry{URL: source.String()}
+
+	if _, entries, err := mf2.Parse(doc.body, doc.url); err == nil && len(entries) > 0 {
+		out = entries[0]
+
+		// prefer the post which is published by source or responds to
+		// target, if page contains several of them
+		for i := range entries {
+			if entries[i].URL == source.String() || references(entries[i], target) {
+				out = entries[i]
+
+				break
+			}
+		}
+	}
+
+	out.Source = source.String()
+
+	if out.URL == "" {
+		out.URL = source.String()
+	}
+
+	if out.Published.IsZero() {
+		out.Published = time.Now().UTC().Truncate(time.Second)
+	}
+
+	return out
+}
+
+// references reports whether entry is a like, repost, bookmark or reply of
+// target.
+func references(e domain.Entry, target *url.URL) bool {
+	expect := withoutFragment(target)
+
+	for _, urls := range [][]string{e.LikeOf, e.RepostOf, e.BookmarkOf, e.InReplyTo} {
+		for _, src := range urls {
+			if u, err := url.Parse(src); err == nil && withoutFragment(u) == expect {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isValid reports whether source and target are different web pages.
+func isValid(source, target *url.URL) bool {
+	return isHTTPURL(source) && isHTTPURL(target) && withoutFragment(source) != withoutFragment(target)
+}
+
+func isHTTPURL(u *url.URL) bool {
+	return u != nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func withoutFragment(u *url.URL) string {
+	out := *u
+	out.Fragment, out.RawFragment = "", ""
+
+	return out.String()
+}
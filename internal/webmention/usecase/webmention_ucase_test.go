@@ -0,0 +1,349 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+	timelineucase "source.toby3d.me/toby3d/sub/internal/timeline/usecase"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
+	"source.toby3d.me/toby3d/sub/internal/webmention"
+	ucase "source.toby3d.me/toby3d/sub/internal/webmention/usecase"
+)
+
+const target string = "https://user.example.com/posts/1"
+
+// pages contains sources of webmentions by their paths.
+var pages = map[string]string{
+	"/like": `<!DOCTYPE html><html><body><div class="h-entry">
+		<a class="p-author h-card" href="https://alice.example.com/">Alice</a> liked
+		<a class="u-like-of" href="` + target + `">a post</a>
+		<a class="u-url" href="/like">permalink</a>
+	</div></body></html>`,
+	"/reply": `<!DOCTYPE html><html><body><div class="h-entry">
+		<a class="u-in-reply-to" href="` + target + `#comments">In reply to</a>
+		<p class="e-content">Great post!</p>
+	</div></body></html>`,
+	"/repost": `<!DOCTYPE html><html><body><div class="h-entry">
+		<a class="u-repost-of" href="` + target + `">Reposted</a>
+	</div></body></html>`,
+	"/mention": `<!DOCTYPE html><html><head><title>Links</title></head><body>
+		<p>See <a href="` + target + `">this</a>.</p>
+	</body></html>`,
+	"/unrelated": `<!DOCTYPE html><html><body><div class="h-entry">
+		<p class="e-content">Nothing to see here.</p>
+	</div></body></html>`,
+}
+
+func TestWebmentionUseCase_Verify(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set(common.HeaderContentType, "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	}))
+	t.Cleanup(srv.Close)
+
+	for name, tc := range map[string]struct {
+		source, target string
+		expectError    error
+		expect         func(e domain.Entry) bool
+	}{
+		"like": {
+			source: srv.URL + "/like", target: target,
+			expect: func(e domain.Entry) bool { return cmp.Equal(e.LikeOf, []string{target}) },
+		},
+		"reply": {
+			source: srv.URL + "/reply", target: target,
+			expect: func(e domain.Entry) bool {
+				return len(e.InReplyTo) == 1 && e.Content != nil && e.Content.Text == "Great post!"
+			},
+		},
+		"repost": {
+			source: srv.URL + "/repost", target: target,
+			expect: func(e domain.Entry) bool { return cmp.Equal(e.RepostOf, []string{target}) },
+		},
+		"mention": {
+			source: srv.URL + "/mention", target: target,
+			expect: func(e domain.Entry) bool { return e.URL == srv.URL+"/mention" },
+		},
+		"no link": {
+			source: srv.URL + "/unrelated", target: target, expectError: webmention.ErrNoLink,
+		},
+		"unknown source": {
+			source: srv.URL + "/unknown", target: target, expectError: webmention.ErrSource,
+		},
+		"unknown target": {
+			source: srv.URL + "/like", target: "https://stranger.example.com/", expectError: webmention.ErrTarget,
+		},
+		"same": {source: target, target: target + "#top", expectError: webmention.ErrRequest},
+		"scheme": {
+			source: "ftp://alice.example.com/like", target: target, expectError: webmention.ErrRequest,
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			webmentions, entries := newUseCase(t, srv.Client())
+			source, _ := url.Parse(tc.source)
+			target, _ := url.Parse(tc.target)
+
+			err := webmentions.Verify(context.Background(), source, target)
+			if !errors.Is(err, tc.expectError) {
+				t.Fatalf("expect %v, got %v", tc.expectError, err)
+			}
+
+			result, err := entries.Fetch(context.Background(), *domain.TestUser(t), common.ChannelNotifications)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expect == nil {
+				if len(result) != 0 {
+					t.Errorf("expect no notifications, got %+v", result)
+				}
+
+				return
+			}
+
+			if len(result) != 1 {
+				t.Fatalf("expect single notification, got %+v", result)
+			}
+
+			if result[0].Source != tc.source || !tc.expect(result[0]) {
+				t.Errorf("unexpected notification %+v", result[0])
+			}
+		})
+	}
+}
+
+func TestWebmentionUseCase_Verify_Update(t *testing.T) {
+	t.Parallel()
+
+	var state int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.LoadInt32(&state) {
+		case 0:
+			_, _ = w.Write([]byte(pages["/reply"]))
+		case 1:
+			_, _ = w.Write([]byte(pages["/unrelated"]))
+		default:
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	webmentions, entries := newUseCase(t, srv.Client())
+	source, _ := url.Parse(srv.URL + "/post")
+	target, _ := url.Parse(target)
+
+	for i, expect := range []int{1, 0, 0} {
+		atomic.StoreInt32(&state, int32(i))
+
+		if err := webmentions.Verify(context.Background(), source, target); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+
+		result, err := entries.Fetch(context.Background(), *domain.TestUser(t), common.ChannelNotifications)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != expect {
+			t.Errorf("#%d: expect %d notifications, got %+v", i, expect, result)
+		}
+	}
+}
+
+func TestWebmentionUseCase_Verify_Owner(t *testing.T) {
+	t.Parallel()
+
+	// source links to the target from query
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(common.HeaderContentType, "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<!DOCTYPE html><a href="` + r.URL.Query().Get("target") + `">link</a>`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	users := usermemoryrepo.NewMemoryUserRepository()
+
+	for _, me := range []string{"https://example.com/", "https://example.com/alice", "https://example.com/alicebob/"} {
+		u, _ := url.Parse(me)
+		if err := users.Create(ctx, domain.User{URL: u}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for target, expect := range map[string]string{
+		"https://example.com/alice":           "https://example.com/alice",
+		"https://example.com/alice/post":      "https://example.com/alice",
+		"https://example.com/alicebob/":       "https://example.com/alicebob/",
+		"https://example.com/alicebob/post":   "https://example.com/alicebob/",
+		"https://example.com/alicebobby/post": "https://example.com/",
+		"https://EXAMPLE.com":                 "https://example.com/",
+	} {
+		entries := timelinememoryrepo.NewMemoryTimelineRepository()
+		webmentions := ucase.NewWebmentionUseCase(ucase.NewWebmentionUseCaseOptions{
+			Client:    srv.Client(),
+			Users:     users,
+			Entries:   entries,
+			Timelines: timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{Entries: entries}),
+		})
+
+		source, _ := url.Parse(srv.URL + "/post?target=" + url.QueryEscape(target))
+		u, _ := url.Parse(target)
+
+		if err := webmentions.Verify(ctx, source, u); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+
+		owner, _ := url.Parse(expect)
+
+		result, err := entries.Fetch(ctx, domain.User{URL: owner}, common.ChannelNotifications)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != 1 {
+			t.Errorf("%s: expect notification of %s, got %+v", target, expect, result)
+		}
+	}
+}
+
+func TestWebmentionUseCase_Verify_Private(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pages["/like"]))
+	}))
+	t.Cleanup(srv.Close)
+
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	webmentions := ucase.NewWebmentionUseCase(ucase.NewWebmentionUseCaseOptions{
+		Users:     newUsers(t),
+		Entries:   entries,
+		Timelines: timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{Entries: entries}),
+	})
+
+	source, _ := url.Parse(srv.URL + "/like")
+	target, _ := url.Parse(target)
+
+	if err := webmentions.Verify(context.Background(), source, target); !errors.Is(err, httpclient.ErrPrivate) {
+		t.Errorf("expect %v, got %v", httpclient.ErrPrivate, err)
+	}
+}
+
+func TestWebmentionUseCase_Receive(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pages[r.URL.Path]))
+	}))
+	t.Cleanup(srv.Close)
+
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	webmentions := ucase.NewWebmentionUseCase(ucase.NewWebmentionUseCaseOptions{
+		Client:    srv.Client(),
+		Users:     newUsers(t),
+		Entries:   entries,
+		Timelines: timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{Entries: entries}),
+		QueueSize: 1,
+	})
+
+	ctx := context.Background()
+	like, _ := url.Parse(srv.URL + "/like")
+	reply, _ := url.Parse(srv.URL + "/reply")
+	target, _ := url.Parse(target)
+	stranger, _ := url.Parse("https://stranger.example.com/")
+
+	for _, tc := range []struct {
+		source, target *url.URL
+		expect         error
+	}{
+		{source: target, target: target, expect: webmention.ErrRequest},
+		{source: like, target: stranger, expect: webmention.ErrTarget},
+		{source: like, target: target},
+		{source: reply, target: target, expect: webmention.ErrQueue},
+	} {
+		if err := webmentions.Receive(ctx, tc.source, tc.target); !errors.Is(err, tc.expect) {
+			t.Errorf("%s: expect %v, got %v", tc.source, tc.expect, err)
+		}
+	}
+
+	// received webmentions are not verified until run
+	result, err := entries.Fetch(ctx, *domain.TestUser(t), common.ChannelNotifications)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != 0 {
+		t.Fatalf("expect no notifications before run, got %+v", result)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	t.Cleanup(cancel)
+
+	go func() { _ = webmentions.Run(runCtx) }()
+
+	for len(result) == 0 && runCtx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+
+		if result, err = entries.Fetch(ctx, *domain.TestUser(t), common.ChannelNotifications); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(result) != 1 || result[0].Source != like.String() {
+		t.Errorf("expect notification of queued webmention, got %+v", result)
+	}
+}
+
+func newUseCase(tb testing.TB, client *http.Client) (webmention.UseCase, timeline.Repository) {
+	tb.Helper()
+
+	entries := timelinememoryrepo.NewMemoryTimelineRepository()
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{Entries: entries})
+
+	return ucase.NewWebmentionUseCase(ucase.NewWebmentionUseCaseOptions{
+		Client:    client,
+		Users:     newUsers(tb),
+		Entries:   entries,
+		Timelines: timelines,
+	}), entries
+}
+
+// newUsers returns registry which knows only domain.TestUser.
+func newUsers(tb testing.TB) user.Repository {
+	tb.Helper()
+
+	users := usermemoryrepo.NewMemoryUserRepository()
+	if err := users.Create(context.Background(), *domain.TestUser(tb)); err != nil {
+		tb.Fatal(err)
+	}
+
+	return users
+}
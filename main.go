@@ -6,25 +6,77 @@ package main
 
 import (
 	"context"
+	"embed"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
+	httppprof "net/http/pprof"
+	"net/url"
 	"os"
 	"os/signal"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 	"syscall"
+	"time"
+
+	authhttpdelivery "source.toby3d.me/toby3d/sub/internal/auth/delivery/http"
+	authucase "source.toby3d.me/toby3d/sub/internal/auth/usecase"
+	blockucase "source.toby3d.me/toby3d/sub/internal/block/usecase"
+	channelucase "source.toby3d.me/toby3d/sub/internal/channel/usecase"
+	"source.toby3d.me/toby3d/sub/internal/common"
+	"source.toby3d.me/toby3d/sub/internal/compactor"
+	"source.toby3d.me/toby3d/sub/internal/config"
+	"source.toby3d.me/toby3d/sub/internal/domain"
+	eventmemory "source.toby3d.me/toby3d/sub/internal/event/memory"
+	"source.toby3d.me/toby3d/sub/internal/feed"
+	"source.toby3d.me/toby3d/sub/internal/feed/atom"
+	"source.toby3d.me/toby3d/sub/internal/feed/jsonfeed"
+	"source.toby3d.me/toby3d/sub/internal/feed/mf2"
+	"source.toby3d.me/toby3d/sub/internal/feed/rss"
+	"source.toby3d.me/toby3d/sub/internal/fetcher"
+	followucase "source.toby3d.me/toby3d/sub/internal/follow/usecase"
+	"source.toby3d.me/toby3d/sub/internal/httpclient"
+	microsubhttpdelivery "source.toby3d.me/toby3d/sub/internal/microsub/delivery/http"
+	muteucase "source.toby3d.me/toby3d/sub/internal/mute/usecase"
+	previewucase "source.toby3d.me/toby3d/sub/internal/preview/usecase"
+	searchucase "source.toby3d.me/toby3d/sub/internal/search/usecase"
+	timelineucase "source.toby3d.me/toby3d/sub/internal/timeline/usecase"
+	webmentionhttpdelivery "source.toby3d.me/toby3d/sub/internal/webmention/delivery/http"
+	webmentionucase "source.toby3d.me/toby3d/sub/internal/webmention/usecase"
 )
 
 var logger = log.New(os.Stdout, "", log.LstdFlags|log.Llongfile)
 
+//go:embed web/static
+var static embed.FS
+
 var (
-	cpuProfilePath, memProfilePath string
-	enablePprof                    bool
+	configPath, cpuProfilePath, memProfilePath string
+	enablePprof, configCheck                   bool
+
+	// overrides contains flags which take precedence over configuration
+	// file and environment, keyed by flag name.
+	overrides = map[string]*string{
+		"addr":           new(string),
+		"base-url":       new(string),
+		"storage":        new(string),
+		"dsn":            new(string),
+		"token-endpoint": new(string),
+	}
 )
 
 func init() {
+	flag.StringVar(&configPath, "config", "", "set path to TOML or YAML configuration file")
+	flag.BoolVar(&configCheck, "config-check", false, "validate configuration and exit")
+	flag.StringVar(overrides["addr"], "addr", "", "set address to listen on")
+	flag.StringVar(overrides["base-url"], "base-url", "", "set public root URL of server")
+	flag.StringVar(overrides["storage"], "storage", "", "set storage backend")
+	flag.StringVar(overrides["dsn"], "dsn", "", "set data source name of storage")
+	flag.StringVar(overrides["token-endpoint"], "token-endpoint", "", "set IndieAuth token endpoint which verifies tokens")
 	flag.BoolVar(&enablePprof, "pprof", false, "enable pprof mode")
 	flag.StringVar(&cpuProfilePath, "cpuprofile", "", "set path to saveing CPU memory profile")
 	flag.StringVar(&memProfilePath, "memprofile", "", "set path to saveing pprof memory profile")
@@ -33,12 +85,119 @@ func init() {
 
 func main() {
 	ctx := context.Background()
-	server := http.Server{
-		Addr:     ":3000",
-		ErrorLog: logger,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, "Hello, World!")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	if configCheck {
+		logger.Println("configuration is valid")
+
+		return
+	}
+
+	endpoint, _ := url.Parse(cfg.Auth.TokenEndpoint)
+
+	repos, err := newRepositories(ctx, cfg.Storage)
+	if err != nil {
+		logger.Fatalln("cannot create storage:", err)
+	}
+	defer repos.Close()
+
+	// token endpoint is trusted and may live in the same network, unlike
+	// URLs provided by users
+	client := &http.Client{Timeout: cfg.Limits.Timeout.Duration}
+	remote := httpclient.New(httpclient.Options{
+		Timeout:      cfg.Limits.Timeout.Duration,
+		AllowPrivate: cfg.Limits.AllowPrivate,
+	})
+	parser := feed.NewParser(jsonfeed.Format{}, atom.Format{}, rss.Format{}, mf2.Format{})
+	events := eventmemory.NewMemoryEventBus()
+	timelines := timelineucase.NewTimelineUseCase(timelineucase.NewTimelineUseCaseOptions{
+		Entries: repos.entries,
+		Mutes:   repos.mutes,
+		Blocks:  repos.blocks,
+		Events:  events,
+	})
+	feeds := fetcher.NewFetcher(fetcher.NewFetcherOptions{
+		Client:     remote,
+		Parser:     parser,
+		Follows:    repos.follows,
+		Timelines:  timelines,
+		Logger:     logger,
+		Interval:   cfg.Fetcher.Interval.Duration,
+		MaxBackoff: cfg.Fetcher.MaxBackoff.Duration,
+		Workers:    cfg.Limits.Workers,
+	})
+	compaction := compactor.NewCompactor(compactor.NewCompactorOptions{
+		Channels:  repos.channels,
+		Users:     repos.users,
+		Timelines: timelines,
+		Logger:    logger,
+		Policy: domain.Retention{
+			MaxAge:     cfg.Retention.MaxAge.Duration,
+			MaxCount:   cfg.Retention.MaxCount,
+			KeepUnread: cfg.Retention.KeepUnread,
+		},
+		Notifications: cfg.Retention.Notifications,
+	})
+	microsub := microsubhttpdelivery.NewHandler(microsubhttpdelivery.NewHandlerOptions{
+		Channels: channelucase.NewChannelUseCase(channelucase.NewChannelUseCaseOptions{
+			Channels: repos.channels,
+			Follows:  repos.follows,
+			Entries:  repos.entries,
+			Users:    repos.users,
+			Events:   events,
 		}),
+		Blocks:    blockucase.NewBlockUseCase(repos.blocks, repos.channels, repos.entries),
+		Events:    events,
+		Follows:   followucase.NewFollowUseCase(repos.follows, repos.channels),
+		Mutes:     muteucase.NewMuteUseCase(repos.mutes, repos.channels, repos.entries),
+		Previews:  previewucase.NewPreviewUseCase(feeds),
+		Search:    searchucase.NewSearchUseCase(remote, parser),
+		Timelines: timelines,
+		Heartbeat: cfg.Limits.Heartbeat.Duration,
+	})
+	webmentions := webmentionucase.NewWebmentionUseCase(webmentionucase.NewWebmentionUseCaseOptions{
+		Client:    remote,
+		Users:     repos.users,
+		Entries:   repos.entries,
+		Timelines: timelines,
+		Logger:    logger,
+	})
+	auth := authhttpdelivery.NewMiddleware(authucase.NewAuthUseCase(authucase.NewAuthUseCaseOptions{
+		Client:   client,
+		Endpoint: endpoint,
+		Users:    repos.users,
+		TTL:      cfg.Limits.TokenTTL.Duration,
+	}))
+
+	assets, err := fs.Sub(static, "web/static")
+	if err != nil {
+		logger.Fatalln("cannot open static assets:", err)
+	}
+
+	router := http.NewServeMux()
+	router.Handle("/microsub", auth.Handler(microsub))
+	router.Handle("/webmention", webmentionhttpdelivery.NewHandler(webmentions))
+	router.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(assets))))
+	router.Handle("/robots.txt", http.FileServer(http.FS(assets)))
+	router.HandleFunc("/health", health)
+
+	if enablePprof {
+		router.HandleFunc("/debug/pprof/", httppprof.Index)
+		router.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+		router.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+		router.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+		router.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+	}
+
+	server := http.Server{
+		Addr:              cfg.Server.Addr,
+		ErrorLog:          logger,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
 
 	done := make(chan os.Signal, 1)
@@ -57,15 +216,46 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	fetchCtx, stopFetch := context.WithCancel(ctx)
+	defer stopFetch()
+
 	go func() {
-		if err := server.ListenAndServe(); err != nil {
+		if err := feeds.Run(fetchCtx, cfg.Fetcher.Tick.Duration); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Fatalln("cannot run feed fetcher:", err)
+		}
+	}()
+
+	go func() {
+		if err := compaction.Run(fetchCtx, cfg.Retention.Interval.Duration); err != nil &&
+			!errors.Is(err, context.Canceled) {
+			logger.Fatalln("cannot run channels compactor:", err)
+		}
+	}()
+
+	go func() {
+		if err := webmentions.Run(fetchCtx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Fatalln("cannot run webmentions verifier:", err)
+		}
+	}()
+
+	go func() {
+		logger.Printf("started at %s, Microsub endpoint is %s, Webmention endpoint is %s", server.Addr,
+			publicURL(cfg.Server.BaseURL, "/microsub"), publicURL(cfg.Server.BaseURL, "/webmention"))
+
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Fatalln("cannot listen and serve:", err)
 		}
 	}()
 
 	<-done
+	stopFetch()
 
-	if err := server.Shutdown(ctx); err != nil {
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// server shutdown does not wait for hijacked and streaming connections,
+	// so events streams are closed by their request contexts
+	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Fatalln("failed shutdown of server:", err)
 	}
 
@@ -84,3 +274,62 @@ func main() {
 		logger.Fatalln("could not write memory profile:", err)
 	}
 }
+
+// loadConfig reads configuration file, environment variables and flags in the
+// order of increasing precedence and validates the result.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(configPath, os.Environ())
+	if err != nil {
+		return nil, fmt.Errorf("cannot load configuration: %w", err)
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		value, ok := overrides[f.Name]
+		if !ok {
+			return
+		}
+
+		switch f.Name {
+		case "addr":
+			cfg.Server.Addr = *value
+		case "base-url":
+			cfg.Server.BaseURL = *value
+		case "storage":
+			cfg.Storage.Backend = *value
+		case "dsn":
+			cfg.Storage.DSN = *value
+		case "token-endpoint":
+			cfg.Auth.TokenEndpoint = *value
+		}
+	})
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// publicURL returns public URL of server endpoint by its path, or just the path
+// if base URL is unknown.
+func publicURL(baseURL, path string) string {
+	if baseURL == "" {
+		return path
+	}
+
+	return strings.TrimSuffix(baseURL, "/") + path
+}
+
+// health reports that server is up and able to respond.
+func health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set(common.HeaderContentType, common.MIMEApplicationJSONCharsetUTF8)
+	w.Header().Set(common.HeaderCacheControl, "no-store")
+	_, _ = w.Write([]byte(`{"status":"ok"}` + "\n"))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"source.toby3d.me/toby3d/sub/internal/author"
+	authorboltrepo "source.toby3d.me/toby3d/sub/internal/author/repository/bolt"
+	authormemoryrepo "source.toby3d.me/toby3d/sub/internal/author/repository/memory"
+	authorpostgresrepo "source.toby3d.me/toby3d/sub/internal/author/repository/postgres"
+	authorsqlite3repo "source.toby3d.me/toby3d/sub/internal/author/repository/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/channel"
+	channelboltrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/bolt"
+	channelmemoryrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/memory"
+	channelpostgresrepo "source.toby3d.me/toby3d/sub/internal/channel/repository/postgres"
+	channelsqlite3repo "source.toby3d.me/toby3d/sub/internal/channel/repository/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/config"
+	"source.toby3d.me/toby3d/sub/internal/database/bolt"
+	"source.toby3d.me/toby3d/sub/internal/database/postgres"
+	"source.toby3d.me/toby3d/sub/internal/database/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/follow"
+	followboltrepo "source.toby3d.me/toby3d/sub/internal/follow/repository/bolt"
+	followmemoryrepo "source.toby3d.me/toby3d/sub/internal/follow/repository/memory"
+	followpostgresrepo "source.toby3d.me/toby3d/sub/internal/follow/repository/postgres"
+	followsqlite3repo "source.toby3d.me/toby3d/sub/internal/follow/repository/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/timeline"
+	timelineboltrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/bolt"
+	timelinememoryrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/memory"
+	timelinepostgresrepo "source.toby3d.me/toby3d/sub/internal/timeline/repository/postgres"
+	timelinesqlite3repo "source.toby3d.me/toby3d/sub/internal/timeline/repository/sqlite3"
+	"source.toby3d.me/toby3d/sub/internal/user"
+	userboltrepo "source.toby3d.me/toby3d/sub/internal/user/repository/bolt"
+	usermemoryrepo "source.toby3d.me/toby3d/sub/internal/user/repository/memory"
+	userpostgresrepo "source.toby3d.me/toby3d/sub/internal/user/repository/postgres"
+	usersqlite3repo "source.toby3d.me/toby3d/sub/internal/user/repository/sqlite3"
+)
+
+// repositories contains storages of all application data, created by single
+// backend.
+type repositories struct {
+	channels channel.Repository
+	follows  follow.Repository
+	mutes    author.Repository
+	blocks   author.Repository
+	entries  timeline.Repository
+	users    user.Repository
+	closers  []io.Closer
+}
+
+var ErrStorage = errors.New("unsupported storage backend")
+
+func newRepositories(ctx context.Context, cfg config.Storage) (*repositories, error) {
+	out := &repositories{
+		channels: channelmemoryrepo.NewMemoryChannelRepository(),
+		follows:  followmemoryrepo.NewMemoryFollowRepository(),
+		mutes:    authormemoryrepo.NewMemoryAuthorRepository(),
+		blocks:   authormemoryrepo.NewMemoryAuthorRepository(),
+		entries:  timelinememoryrepo.NewMemoryTimelineRepository(),
+		users:    usermemoryrepo.NewMemoryUserRepository(),
+	}
+
+	switch cfg.Backend {
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrStorage, cfg.Backend)
+	case config.StorageMemory:
+	case config.StorageBolt:
+		db, err := bolt.Open(cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open bolt database: %w", err)
+		}
+
+		out.channels = channelboltrepo.NewBoltChannelRepository(db)
+		out.follows = followboltrepo.NewBoltFollowRepository(db)
+		out.mutes = authorboltrepo.NewBoltAuthorRepository(db, author.ListMutes)
+		out.blocks = authorboltrepo.NewBoltAuthorRepository(db, author.ListBlocks)
+		out.entries = timelineboltrepo.NewBoltTimelineRepository(db)
+		out.users = userboltrepo.NewBoltUserRepository(db)
+		out.closers = append(out.closers, db)
+	case config.StoragePostgres:
+		db, err := postgres.Open(ctx, cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open PostgreSQL database: %w", err)
+		}
+
+		out.channels = channelpostgresrepo.NewPostgresChannelRepository(db)
+		out.follows = followpostgresrepo.NewPostgresFollowRepository(db)
+		out.mutes = authorpostgresrepo.NewPostgresAuthorRepository(db, author.ListMutes)
+		out.blocks = authorpostgresrepo.NewPostgresAuthorRepository(db, author.ListBlocks)
+		out.entries = timelinepostgresrepo.NewPostgresTimelineRepository(db)
+		out.users = userpostgresrepo.NewPostgresUserRepository(db)
+		out.closers = append(out.closers, db)
+	case config.StorageSQLite3:
+		db, err := sqlite3.Open(ctx, cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open SQLite database: %w", err)
+		}
+
+		out.channels = channelsqlite3repo.NewSQLite3ChannelRepository(db)
+		out.follows = followsqlite3repo.NewSQLite3FollowRepository(db)
+		out.mutes = authorsqlite3repo.NewSQLite3AuthorRepository(db, author.ListMutes)
+		out.blocks = authorsqlite3repo.NewSQLite3AuthorRepository(db, author.ListBlocks)
+		out.entries = timelinesqlite3repo.NewSQLite3TimelineRepository(db)
+		out.users = usersqlite3repo.NewSQLite3UserRepository(db)
+		out.closers = append(out.closers, db)
+	}
+
+	return out, nil
+}
+
+// Close releases connections of all storages.
+func (repos *repositories) Close() error {
+	errs := make([]error, 0, len(repos.closers))
+
+	for i := range repos.closers {
+		if err := repos.closers[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}